@@ -0,0 +1,69 @@
+package encoding
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ASCII is strict 7-bit US-ASCII. Encode rejects every other character
+// and Decode replaces bytes above 0x7F with RuneError.
+var ASCII *asciiEncoding = &asciiEncoding{splitter: &splitter{}}
+
+type asciiEncoding struct {
+	*splitter
+}
+
+func (asciiEncoding) String() string {
+	return "ASCII"
+}
+
+func (asciiEncoding) NewEncodingSearcher() EncodingSearcher {
+	return asciiSearcher{}
+}
+
+func (asciiEncoding) newValidator() EncodingSearcher {
+	return asciiSearcher{validate: true}
+}
+
+// asciiSearcher accepts 7-bit text without escape sequences, which belong
+// to ISO-2022. With validate set, it accepts every 7-bit byte.
+type asciiSearcher struct {
+	validate bool
+}
+
+func (s asciiSearcher) EncodingSearch(p []byte, atEOF bool) (nSrc int, err error, score int) {
+	for ; nSrc < len(p); nSrc++ {
+		c0 := p[nSrc]
+		if c0 >= RuneSelf || !s.validate && (c0 == 0x00 || c0 == asciiEsc) {
+			return nSrc, ErrInvalidEncoding, 0
+		}
+	}
+	return nSrc, nil, 0
+}
+
+// Decode reads bytes above 0x7F as RuneError, and returns
+// ErrInvalidEncoding with the text.
+func (c *asciiEncoding) Decode(b []byte) (string, error) {
+	var buf strings.Builder
+	var err error
+	for _, c0 := range b {
+		if c0 < utf8.RuneSelf {
+			buf.WriteByte(c0)
+		} else {
+			buf.WriteRune(RuneError)
+			err = ErrInvalidEncoding
+		}
+	}
+	return buf.String(), err
+}
+
+func (c *asciiEncoding) Encode(s string) ([]byte, error) {
+	ret := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r >= utf8.RuneSelf {
+			return nil, ErrUnmappable
+		}
+		ret = append(ret, byte(r))
+	}
+	return ret, nil
+}
@@ -0,0 +1,21 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/traditionalchinese"
+	"golang.org/x/text/transform"
+)
+
+// Big5 is Big5 (CP950) of traditional Chinese, with the HKSCS extensions
+// that the WHATWG Encoding Standard includes.
+var Big5 *dbcsEncoding = newDBCSEncoding("Big5", big5Table, nil, zhHantModel,
+	func() transform.Transformer { return traditionalchinese.Big5.NewDecoder() },
+	func() transform.Transformer { return traditionalchinese.Big5.NewEncoder() })
+
+var big5Table = &dbcsTable{
+	newDecoder: func() transform.Transformer {
+		return traditionalchinese.Big5.NewDecoder()
+	},
+	core: func(c0, c1 byte) bool {
+		return 0xa1 <= c0 && c0 <= 0xf9
+	},
+}
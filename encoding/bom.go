@@ -0,0 +1,74 @@
+package encoding
+
+import (
+	"bytes"
+)
+
+// ByteOrderMarker is implemented by encodings that have a byte order mark.
+type ByteOrderMarker interface {
+	BOM() []byte
+}
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// boms lists the byte order marks, longest first where one is a prefix of
+// another.
+var boms = []struct {
+	bom []byte
+	enc Encoding
+}{
+	{utf8BOM, UTF8B},
+	{utf32LEBOM, UTF32},
+	{utf32BEBOM, UTF32B},
+	{utf16LEBOM, UTF16},
+	{utf16BEBOM, UTF16B},
+}
+
+// maxBOMLen is the length of the longest byte order mark.
+const maxBOMLen = 4
+
+// SniffBOM returns the encoding announced by a byte order mark at the
+// start of p, and the mark itself. It returns nil if p has none.
+func SniffBOM(p []byte) (Encoding, []byte) {
+	for _, b := range boms {
+		if bytes.HasPrefix(p, b.bom) {
+			return b.enc, b.bom
+		}
+	}
+	return nil, nil
+}
+
+// HasBOM reports whether p starts with the byte order mark of enc.
+func HasBOM(p []byte, enc Encoding) bool {
+	bm, ok := enc.(ByteOrderMarker)
+	return ok && bytes.HasPrefix(p, bm.BOM())
+}
+
+// TrimBOM returns p without the byte order mark of enc at its start.
+func TrimBOM(p []byte, enc Encoding) []byte {
+	if HasBOM(p, enc) {
+		return p[len(enc.(ByteOrderMarker).BOM()):]
+	}
+	return p
+}
+
+// bomEncoder is implemented by encodings whose Encode may put their byte
+// order mark in front of its output.
+type bomEncoder interface {
+	ByteOrderMarker
+	encodesBOM() bool
+}
+
+// trimEncodedBOM returns b, the output of one Encode call of enc, without
+// the byte order mark enc put in front of it. A U+FEFF that starts the
+// encoded text itself is kept.
+func trimEncodedBOM(b []byte, enc Encoder) []byte {
+	if e, ok := enc.(bomEncoder); ok && e.encodesBOM() {
+		return bytes.TrimPrefix(b, e.BOM())
+	}
+	return b
+}
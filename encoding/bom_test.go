@@ -0,0 +1,81 @@
+package encoding
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSniffBOM(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want Encoding
+		bom  []byte
+	}{
+		{[]byte("\xef\xbb\xbfabc"), UTF8B, utf8BOM},
+		{[]byte("\xff\xfe\x00\x00a\x00\x00\x00"), UTF32, utf32LEBOM},
+		{[]byte("\x00\x00\xfe\xff\x00\x00\x00a"), UTF32B, utf32BEBOM},
+		{[]byte("\xff\xfea\x00"), UTF16, utf16LEBOM},
+		{[]byte("\xfe\xff\x00a"), UTF16B, utf16BEBOM},
+		{[]byte("abc"), nil, nil},
+		{[]byte("\xef\xbb"), nil, nil},
+	}
+	for _, tt := range tests {
+		enc, bom := SniffBOM(tt.in)
+		if name(enc) != name(tt.want) || !bytes.Equal(bom, tt.bom) {
+			t.Errorf("SniffBOM(% x) = %s, % x; want %s, % x", tt.in, name(enc), bom, name(tt.want), tt.bom)
+		}
+	}
+}
+
+func TestDetectionBOM(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		only []Encoding
+		want Encoding
+		bom  bool
+	}{
+		{"UTF-8", []byte("\xef\xbb\xbfabc\n"), nil, UTF8B, true},
+		{"UTF-16", []byte("\xff\xfea\x00\n\x00"), nil, UTF16, true},
+		{"allowed", []byte("\xff\xfea\x00\n\x00"), []Encoding{UTF16LE}, UTF16, true},
+		{"not allowed", []byte("\xef\xbb\xbfabc\n"), []Encoding{EUCJP}, nil, false},
+		{"none", []byte("abc\n"), nil, ASCII, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			det := detect(t, tt.in, tt.only...)
+			if name(det.Encoding) != name(tt.want) {
+				t.Errorf("Encoding = %s, want %s", name(det.Encoding), name(tt.want))
+			}
+			if (det.BOM != nil) != tt.bom {
+				t.Errorf("BOM = % x", det.BOM)
+			}
+			if tt.bom && (det.Confidence != 1 || det.Ambiguous) {
+				t.Errorf("Confidence = %v, Ambiguous = %v; a byte order mark settles the detection", det.Confidence, det.Ambiguous)
+			}
+		})
+	}
+}
+
+func TestTrimEncodedBOM(t *testing.T) {
+	tests := []struct {
+		enc  Encoding
+		s    string
+		want []byte
+	}{
+		{UTF8, "\ufeffb", []byte("\xef\xbb\xbfb")},
+		{UTF8B, "\ufeffb", []byte("\xef\xbb\xbfb")},
+		{UTF16LE, "\ufeffb", []byte("\xff\xfeb\x00")},
+		{UTF16, "b", []byte("b\x00")},
+		{UTF16, "\ufeffb", []byte("\xff\xfeb\x00")},
+		{UTF16B, "\ufeffb", []byte("\xfe\xff\x00b")},
+		{UTF32, "\ufeffb", []byte("\xff\xfe\x00\x00b\x00\x00\x00")},
+		{UTF32BE, "\ufeffb", []byte("\x00\x00\xfe\xff\x00\x00\x00b")},
+	}
+	for _, tt := range tests {
+		got := trimEncodedBOM(mustEncode(t, tt.enc, tt.s), tt.enc)
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%s: %q encodes to % x without its byte order mark, want % x", tt.enc, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package encoding
+
+import (
+	"bytes"
+	"code.google.com/p/go.text/encoding/japanese"
+	"golang.org/x/text/transform"
+	"sync"
+	"unicode/utf8"
+)
+
+// CP932 is Windows-31J, Microsoft's Shift JIS with the NEC special
+// characters (row 13), the NEC-selected IBM extensions (0xED-0xEE), the IBM
+// extensions (0xFA-0xFC) and user-defined characters (0xF0-0xF9) mapped to
+// the private use area.
+var CP932 *cp932 = newCP932()
+
+type cp932 struct {
+	*splitter
+}
+
+func (cp932) String() string {
+	return "CP932"
+}
+
+func newCP932() *cp932 {
+	return &cp932{
+		splitter: &splitter{},
+	}
+}
+
+func (cp932) NewEncodingSearcher() EncodingSearcher {
+	return newCP932Decoder()
+}
+
+func (cp932) newValidator() EncodingSearcher {
+	d := newCP932Decoder()
+	d.validate = true
+	return d
+}
+
+const (
+	cp932Trails = 188 // 0x40-0x7e, 0x80-0xfc
+	eudcFirst   = 0xe000
+	eudcLast    = 0xe757
+)
+
+var (
+	cp932Once   sync.Once
+	cp932Decode []rune         // by cp932Index
+	cp932Encode map[rune]int32 // to cp932Index
+)
+
+// cp932Index returns the table index of a double-byte character, or -1.
+func cp932Index(c0, c1 byte) int {
+	var lead int
+	switch {
+	case 0x81 <= c0 && c0 < 0xa0:
+		lead = int(c0) - 0x81
+	case 0xe0 <= c0 && c0 < 0xfd:
+		lead = int(c0) - 0xe0 + 0x1f
+	default:
+		return -1
+	}
+	switch {
+	case 0x40 <= c1 && c1 < 0x7f:
+		return lead*cp932Trails + int(c1) - 0x40
+	case 0x80 <= c1 && c1 < 0xfd:
+		return lead*cp932Trails + int(c1) - 0x41
+	}
+	return -1
+}
+
+func cp932Bytes(i int) (c0, c1 byte) {
+	lead, trail := i/cp932Trails, i%cp932Trails
+	if lead < 0x1f {
+		c0 = byte(0x81 + lead)
+	} else {
+		c0 = byte(0xe0 + lead - 0x1f)
+	}
+	if trail < 0x3f {
+		c1 = byte(0x40 + trail)
+	} else {
+		c1 = byte(0x41 + trail)
+	}
+	return c0, c1
+}
+
+// cp932Rank orders the codes of a character that has several, the way
+// Microsoft's encoder picks them: JIS X 0208 first, then the NEC special
+// characters, the IBM extensions and the NEC-selected IBM extensions.
+func cp932Rank(c0 byte) int {
+	switch {
+	case c0 == 0x87:
+		return 1
+	case 0xfa <= c0:
+		return 2
+	case 0xed <= c0 && c0 <= 0xee:
+		return 3
+	}
+	return 0
+}
+
+// cp932Compat maps the JIS forms of characters that CP932 has in Microsoft
+// forms to their codes when encoding, so that text from eucJP-ms converts
+// back.
+var cp932Compat = map[rune][2]byte{
+	0x301c: {0x81, 0x60}, // WAVE DASH
+	0x2016: {0x81, 0x61}, // DOUBLE VERTICAL LINE
+	0x2212: {0x81, 0x7c}, // MINUS SIGN
+	0x00a2: {0x81, 0x91}, // CENT SIGN
+	0x00a3: {0x81, 0x92}, // POUND SIGN
+	0x00ac: {0x81, 0xca}, // NOT SIGN
+}
+
+func buildCP932() {
+	n := 0x3c * cp932Trails
+	cp932Decode = make([]rune, n)
+	cp932Encode = make(map[rune]int32)
+	d := japanese.ShiftJIS.NewDecoder()
+	for i := 0; i < n; i++ {
+		c0, c1 := cp932Bytes(i)
+		var r rune
+		if 0xf0 <= c0 && c0 < 0xfa {
+			r = eudcFirst + rune(i-cp932Index(0xf0, 0x40))
+		} else {
+			dst, _, err := transform.Bytes(d, []byte{c0, c1})
+			var size int
+			r, size = utf8.DecodeRune(dst)
+			if err != nil || size != len(dst) {
+				r = RuneError
+			}
+		}
+		cp932Decode[i] = r
+		if r == RuneError {
+			continue
+		}
+		if old, ok := cp932Encode[r]; ok {
+			o0, _ := cp932Bytes(int(old))
+			if cp932Rank(o0) <= cp932Rank(c0) {
+				continue
+			}
+		}
+		cp932Encode[r] = int32(i)
+	}
+	for r, b := range cp932Compat {
+		if _, ok := cp932Encode[r]; !ok {
+			cp932Encode[r] = int32(cp932Index(b[0], b[1]))
+		}
+	}
+}
+
+func cp932Rune(c0, c1 byte) rune {
+	cp932Once.Do(buildCP932)
+	i := cp932Index(c0, c1)
+	if i < 0 {
+		return RuneError
+	}
+	return cp932Decode[i]
+}
+
+// cp932Decoder validates Windows-31J and scores it like shiftJISDecoder.
+// With validate set, it accepts NUL bytes.
+type cp932Decoder struct {
+	lm       lmScorer
+	validate bool
+}
+
+func newCP932Decoder() *cp932Decoder {
+	return &cp932Decoder{lm: lmScorer{m: jaModel}}
+}
+
+func (d *cp932Decoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	size := 0
+loop:
+	for ; nSrc < len(src); nSrc += size {
+		switch c0 := src[nSrc]; {
+		case c0 == 0x00 && !d.validate:
+			err = ErrInvalidEncoding
+			break loop
+
+		case c0 < utf8.RuneSelf:
+			size = 1
+			d.lm.add(rune(c0), size)
+
+		case 0xa1 <= c0 && c0 < 0xe0:
+			size = 1
+			d.lm.add(rune(c0)+(0xff61-0xa1), size)
+
+		case (0x81 <= c0 && c0 < 0xa0) || (0xe0 <= c0 && c0 < 0xfd):
+			if nSrc+1 >= len(src) {
+				err = transform.ErrShortSrc
+				break loop
+			}
+			r := cp932Rune(c0, src[nSrc+1])
+			if r == RuneError {
+				err = ErrInvalidEncoding
+				break loop
+			}
+			size = 2
+			if 0xed <= c0 && c0 <= 0xee {
+				// The NEC-selected IBM extensions repeat 0xFA-0xFC and
+				// Microsoft's encoder never writes them, so they tell
+				// nothing about the text.
+				r = RuneError
+			}
+			d.lm.add(r, size)
+
+		default:
+			err = ErrInvalidEncoding
+			break loop
+		}
+	}
+	if atEOF && err == transform.ErrShortSrc {
+		err = ErrInvalidEncoding
+	}
+	return nSrc, err, d.lm.take()
+}
+
+// Decode reads each byte that starts no character as RuneError, and
+// returns ErrInvalidEncoding with the text.
+func (c *cp932) Decode(b []byte) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	for i := 0; i < len(b); {
+		c0 := b[i]
+		switch {
+		case c0 < utf8.RuneSelf:
+			buf.WriteByte(c0)
+			i++
+		case 0xa1 <= c0 && c0 < 0xe0:
+			buf.WriteRune(rune(c0) + (0xff61 - 0xa1))
+			i++
+		case i+1 < len(b) && cp932Index(c0, b[i+1]) >= 0:
+			// Codes in the ranges with no character read as RuneError.
+			r := cp932Rune(c0, b[i+1])
+			if r == RuneError {
+				err = ErrInvalidEncoding
+			}
+			buf.WriteRune(r)
+			i += 2
+		default:
+			buf.WriteRune(RuneError)
+			err = ErrInvalidEncoding
+			i++
+		}
+	}
+	return buf.String(), err
+}
+
+func (c *cp932) Encode(s string) ([]byte, error) {
+	cp932Once.Do(buildCP932)
+	ret := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			ret = append(ret, byte(r))
+		case 0xff61 <= r && r < 0xffa0:
+			ret = append(ret, byte(r-(0xff61-0xa1)))
+		default:
+			i, ok := cp932Encode[r]
+			if !ok {
+				return nil, ErrUnmappable
+			}
+			c0, c1 := cp932Bytes(int(i))
+			ret = append(ret, c0, c1)
+		}
+	}
+	return ret, nil
+}
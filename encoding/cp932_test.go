@@ -0,0 +1,73 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestCP932(t *testing.T) {
+	tests := []struct {
+		s string
+		b []byte
+	}{
+		{"日本語", []byte("\x93\xfa\x96\x7b\x8c\xea")},
+		{"ｱ", []byte("\xb1")},
+		{"①㈱Ⅰ", []byte("\x87\x40\x87\x8a\x87\x54")}, // NEC special characters
+		{"ⅰ纊", []byte("\xfa\x40\xfa\x5c")},          // IBM extensions
+		{"～￢", []byte("\x81\x60\x81\xca")},
+		{"\ue000\ue757", []byte("\xf0\x40\xf9\xfc")}, // user-defined characters
+	}
+	for _, tt := range tests {
+		if b := mustEncode(t, CP932, tt.s); !bytes.Equal(b, tt.b) {
+			t.Errorf("Encode(%q) = % x, want % x", tt.s, b, tt.b)
+		}
+		if s, err := CP932.Decode(tt.b); s != tt.s || err != nil {
+			t.Errorf("Decode(% x) = %q, %v; want %q", tt.b, s, err, tt.s)
+		}
+	}
+}
+
+func TestCP932Decode(t *testing.T) {
+	tests := []struct {
+		b    []byte
+		want string
+		err  error
+	}{
+		// The NEC-selected IBM extensions read as the IBM extensions,
+		// which Encode prefers.
+		{[]byte("\xed\x40\xee\xef"), "纊ⅰ", nil},
+		{[]byte("\x87\x90"), "≒", nil},
+
+		// Unassigned codes in the two-byte ranges.
+		{[]byte("a\x85\x40b"), "a\ufffdb", ErrInvalidEncoding},
+		{[]byte("\x81\xad"), "\ufffd", ErrInvalidEncoding},
+		{[]byte("\xa0"), "\ufffd", ErrInvalidEncoding},
+	}
+	for _, tt := range tests {
+		if s, err := CP932.Decode(tt.b); s != tt.want || err != tt.err {
+			t.Errorf("Decode(% x) = %q, %v; want %q, %v", tt.b, s, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestDetectionNeedsCP932(t *testing.T) {
+	tests := []struct {
+		s   string
+		err error
+	}{
+		{"①から⑩まで\n", ErrVendorExtension},
+		{"ⅰ、ⅱ、ⅲ\n", ErrVendorExtension},
+		{"日本語のテキスト\n", nil},
+	}
+	for _, tt := range tests {
+		det := detect(t, mustEncode(t, CP932, tt.s))
+		if det.Encoding != CP932 {
+			t.Errorf("%q detected as %s", tt.s, name(det.Encoding))
+		}
+		c, _ := det.Lookup(ShiftJIS)
+		if c.Valid != (tt.err == nil) || !errors.Is(c.Err, tt.err) {
+			t.Errorf("%q: Shift JIS candidate has Valid %v, Err %v; want Err %v", tt.s, c.Valid, c.Err, tt.err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package encoding
+
+import (
+	"bytes"
+	"golang.org/x/text/transform"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// dbcsTable holds the double-byte characters of a Chinese or Korean code
+// page by lead byte 0x81-0xFE and trail byte 0x40-0xFF, built on first use
+// from the code page's decoder. Unassigned codes hold RuneError.
+type dbcsTable struct {
+	once       sync.Once
+	newDecoder func() transform.Transformer
+	decode     []rune
+
+	// core reports whether a code is in the national standard that the
+	// code page extends. The extensions reuse the byte ranges of Shift JIS,
+	// so the searcher scores them as invalid.
+	core func(c0, c1 byte) bool
+
+	// euro is set if the code page reads 0x80 as the euro sign.
+	euro bool
+}
+
+const dbcsTrails = 0xc0
+
+func (t *dbcsTable) rune(c0, c1 byte) rune {
+	t.once.Do(func() {
+		t.decode = make([]rune, 0x7e*dbcsTrails)
+		d := t.newDecoder()
+		for i := range t.decode {
+			d.Reset()
+			dst, _, err := transform.Bytes(d, []byte{byte(0x81 + i/dbcsTrails), byte(0x40 + i%dbcsTrails)})
+			r, size := utf8.DecodeRune(dst)
+			if err != nil || size != len(dst) {
+				r = RuneError
+			}
+			t.decode[i] = r
+		}
+	})
+	if c0 < 0x81 || 0xfe < c0 || c1 < 0x40 {
+		return RuneError
+	}
+	return t.decode[int(c0-0x81)*dbcsTrails+int(c1-0x40)]
+}
+
+// euc94 reports whether both bytes are in 0xA1-0xFE, the 94x94 set of
+// GB 2312 and KS X 1001.
+func euc94(c0, c1 byte) bool {
+	return 0xa1 <= c0 && c0 <= 0xfe && 0xa1 <= c1 && c1 <= 0xfe
+}
+
+// dbcsDecoder validates a double-byte code page against its table and
+// scores it with the model of its language. With four set, it also reads
+// the four-byte sequences of GB 18030. With validate set, it accepts what
+// Decode reads: NUL bytes and the euro sign.
+type dbcsDecoder struct {
+	t        *dbcsTable
+	four     func([]byte) rune
+	lm       lmScorer
+	validate bool
+}
+
+func (d *dbcsDecoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	size := 0
+loop:
+	for ; nSrc < len(src); nSrc += size {
+		switch c0 := src[nSrc]; {
+		case c0 == 0x00 && !d.validate:
+			err = ErrInvalidEncoding
+			break loop
+
+		case c0 < utf8.RuneSelf:
+			size = 1
+			d.lm.add(rune(c0), size)
+
+		case c0 == 0x80 && d.t.euro && d.validate:
+			size = 1
+
+		case 0x81 <= c0 && c0 <= 0xfe:
+			if nSrc+1 >= len(src) {
+				err = transform.ErrShortSrc
+				break loop
+			}
+			c1 := src[nSrc+1]
+			if d.four != nil && '0' <= c1 && c1 <= '9' {
+				if nSrc+3 >= len(src) {
+					err = transform.ErrShortSrc
+					break loop
+				}
+				r := d.four(src[nSrc : nSrc+4])
+				if r == RuneError {
+					err = ErrInvalidEncoding
+					break loop
+				}
+				size = 4
+				d.lm.add(r, size)
+				break
+			}
+			r := d.t.rune(c0, c1)
+			if r == RuneError {
+				err = ErrInvalidEncoding
+				break loop
+			}
+			size = 2
+			if !d.t.core(c0, c1) {
+				r = RuneError
+			}
+			d.lm.add(r, size)
+
+		default:
+			err = ErrInvalidEncoding
+			break loop
+		}
+	}
+	if atEOF && err == transform.ErrShortSrc {
+		err = ErrInvalidEncoding
+	}
+	return nSrc, err, d.lm.take()
+}
+
+// dbcsEncoding is a Chinese or Korean code page that decodes and encodes
+// through its x/text implementation.
+type dbcsEncoding struct {
+	*splitter
+
+	name       string
+	four       bool // GB 18030 four-byte sequences
+	newSearch  func(validate bool) *dbcsDecoder
+	newDecoder func() transform.Transformer
+	newEncoder func() transform.Transformer
+
+	decoder transform.Transformer
+	encoder transform.Transformer
+}
+
+func (e dbcsEncoding) String() string {
+	return e.name
+}
+
+func (e *dbcsEncoding) NewEncodingSearcher() EncodingSearcher {
+	return e.newSearch(false)
+}
+
+func (e *dbcsEncoding) newValidator() EncodingSearcher {
+	return e.newSearch(true)
+}
+
+func (c *dbcsEncoding) getDecoder() transform.Transformer {
+	if c.decoder == nil {
+		c.decoder = c.newDecoder()
+	} else {
+		c.decoder.Reset()
+	}
+
+	return c.decoder
+}
+
+func (c *dbcsEncoding) Decode(b []byte) (string, error) {
+	ret, err := ioutil.ReadAll(transform.NewReader(bytes.NewReader(b), c.getDecoder()))
+	if err != nil {
+		return "", err
+	}
+	return string(ret), err
+}
+
+func (c *dbcsEncoding) getEncoder() transform.Transformer {
+	if c.encoder == nil {
+		c.encoder = c.newEncoder()
+	} else {
+		c.encoder.Reset()
+	}
+
+	return c.encoder
+}
+
+func (c *dbcsEncoding) Encode(s string) ([]byte, error) {
+	ret, err := ioutil.ReadAll(transform.NewReader(strings.NewReader(s), c.getEncoder()))
+	if err != nil {
+		return nil, err
+	}
+	return ret, err
+}
+
+func newDBCSEncoding(name string, t *dbcsTable, four func([]byte) rune, m *langModel, newDecoder, newEncoder func() transform.Transformer) *dbcsEncoding {
+	return &dbcsEncoding{
+		splitter: &splitter{},
+		name:     name,
+		four:     four != nil,
+		newSearch: func(validate bool) *dbcsDecoder {
+			return &dbcsDecoder{t: t, four: four, lm: lmScorer{m: m}, validate: validate}
+		},
+		newDecoder: newDecoder,
+		newEncoder: newEncoder,
+	}
+}
@@ -0,0 +1,79 @@
+package encoding
+
+import (
+	"testing"
+)
+
+const (
+	zhHansText = "今天天气很好，我们去公园散步吧。\n这是一个简体中文的例子。\n"
+	zhHantText = "今天天氣很好，我們去公園散步吧。\n這是一個繁體中文的例子。\n"
+	koText     = "오늘은 날씨가 좋습니다. 공원에 산책하러 갑시다.\n한국어 예문입니다.\n"
+)
+
+func TestDBCS(t *testing.T) {
+	tests := []struct {
+		enc *dbcsEncoding
+		s   string
+		b   string
+	}{
+		{GBK, "中文", "\xd6\xd0\xce\xc4"},
+		{GBK, "€", "\x80"},
+		{GB18030, "中文", "\xd6\xd0\xce\xc4"},
+		{GB18030, "𠀀", "\x95\x32\x82\x36"},
+		{Big5, "中文", "\xa4\xa4\xa4\xe5"},
+		{EUCKR, "한국", "\xc7\xd1\xb1\xb9"},
+	}
+	for _, tt := range tests {
+		if b := mustEncode(t, tt.enc, tt.s); string(b) != tt.b {
+			t.Errorf("%s: Encode(%q) = % x, want % x", tt.enc, tt.s, b, tt.b)
+		}
+		if s, err := tt.enc.Decode([]byte(tt.b)); s != tt.s || err != nil {
+			t.Errorf("%s: Decode(% x) = %q, %v; want %q", tt.enc, tt.b, s, err, tt.s)
+		}
+	}
+}
+
+func TestDBCSSearcher(t *testing.T) {
+	tests := []struct {
+		enc   *dbcsEncoding
+		in    string
+		valid bool
+	}{
+		{GBK, "\xd6\xd0\xce\xc4", true},
+		{GBK, "\x95\x32\x82\x36", false}, // four-byte sequence
+		{GB18030, "\x95\x32\x82\x36", true},
+		{GB18030, "\x95\x32\x82", false},
+		{Big5, "\xa4\xa4\xa4\xe5", true},
+		{Big5, "\xa4\x30", false},
+		{EUCKR, "\xc7\xd1\xb1\xb9", true},
+		{EUCKR, "\xc7\xd1\xb1", false},
+		{EUCKR, "a\x00b", false},
+	}
+	for _, tt := range tests {
+		ok, _ := CheckEncodingOf(chunks([]byte(tt.in), 3), tt.enc)
+		if ok != tt.valid {
+			t.Errorf("%s: CheckEncodingOf(% x) = %v, want %v", tt.enc, tt.in, ok, tt.valid)
+		}
+	}
+}
+
+func TestDBCSDetection(t *testing.T) {
+	tests := []struct {
+		enc  Encoding
+		s    string
+		want Encoding
+	}{
+		{GBK, zhHansText, GBK},
+		{GB18030, zhHansText + "𠀀\n", GB18030},
+		{Big5, zhHantText, Big5},
+		{EUCKR, koText, EUCKR},
+		// Japanese files must not be taken for Chinese or Korean.
+		{ShiftJIS, jaText, CP932},
+		{EUCJP, jaText, EUCJP},
+	}
+	for _, tt := range tests {
+		if det := detect(t, mustEncode(t, tt.enc, tt.s)); det.Encoding != tt.want {
+			t.Errorf("%s text detected as %s, want %s", tt.enc, name(det.Encoding), name(tt.want))
+		}
+	}
+}
@@ -0,0 +1,325 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/text/transform"
+	"sort"
+)
+
+// Candidate is one encoding considered by a Detector.
+type Candidate struct {
+	Encoding Encoding
+
+	// Score is the evidence collected by the encoding's searcher, e.g. the
+	// number of multibyte characters or escape sequences, or the bits a
+	// language model gains over random bytes. It may be negative.
+	Score int
+
+	// Confidence is the candidate's share of the weighted evidence among
+	// all valid candidates, in the range [0, 1].
+	Confidence float64
+
+	// Valid reports whether the input is well-formed in Encoding.
+	Valid bool
+
+	// Reason tells why the candidate was accepted or rejected.
+	Reason string
+
+	// Err is the error that rejected the candidate, nil if it is valid.
+	// Shift JIS input that needs CP932 fails with ErrVendorExtension.
+	Err error
+
+	priority int
+	band     int // priority of the candidate's family
+	weight   float64
+	high     int // bytes from 0x80 checked
+}
+
+// Detection is the result of encoding detection.
+type Detection struct {
+	// Encoding is the selected encoding, or nil if no candidate is valid.
+	Encoding Encoding
+
+	// Confidence is the confidence of the selected encoding.
+	Confidence float64
+
+	// Ambiguous is set when more than one candidate is valid.
+	Ambiguous bool
+
+	// BOM is the byte order mark the input starts with, if any. A byte
+	// order mark settles the detection.
+	BOM []byte
+
+	// Candidates lists every candidate, best first.
+	Candidates []Candidate
+}
+
+// decisiveConfidence is the lead at which a Detector stops reading early.
+const decisiveConfidence = 0.9
+
+// checker runs one EncodingSearcher over a chunked input.
+type checker struct {
+	Entry
+	es EncodingSearcher
+
+	prv    []byte
+	base   int64
+	err    error
+	score  int
+	high   int
+	failed bool
+	offset int64
+}
+
+func (c *checker) feed(p []byte, atEOF bool) {
+	if c.failed {
+		return
+	}
+	b := p
+	if len(c.prv) > 0 {
+		b = append(append([]byte{}, c.prv...), p...)
+	}
+	if len(b) == 0 {
+		return
+	}
+
+	nSrc, err, s := c.es.EncodingSearch(b, atEOF)
+	c.score += s
+	c.err = err
+	for _, x := range b[:nSrc] {
+		if x >= RuneSelf {
+			c.high++
+		}
+	}
+	switch err {
+	case nil:
+		c.prv = nil
+		c.base += int64(len(b))
+	case transform.ErrShortSrc:
+		c.prv = b[nSrc:]
+		c.base += int64(nSrc)
+	default:
+		c.failed = true
+		c.offset = c.base + int64(nSrc)
+	}
+}
+
+func (c *checker) valid() bool {
+	return !c.failed
+}
+
+// A Detector guesses the encoding of an input fed to it in chunks.
+type Detector struct {
+	checkers []*checker
+	only     []Encoding
+
+	// byte order mark stage
+	sniffBOM bool
+	head     []byte
+	bomEnc   Encoding
+	bom      []byte
+}
+
+// NewDetector returns a Detector for the encodings of DefaultRegistry,
+// restricted to only if it is not empty. It fails if an encoding of only
+// is not registered.
+func NewDetector(only ...Encoding) (*Detector, error) {
+	return DefaultRegistry.NewDetector(only...)
+}
+
+// Feed passes the next chunk of the input to every candidate.
+// atEOF must be set on the last chunk.
+func (d *Detector) Feed(p []byte, atEOF bool) {
+	if d.sniffBOM {
+		d.head = append(d.head, p...)
+		if len(d.head) < maxBOMLen && !atEOF {
+			return
+		}
+		d.sniffBOM = false
+		if enc, bom := SniffBOM(d.head); enc != nil && d.allowsBOM(bom) {
+			d.bomEnc, d.bom = enc, bom
+			return
+		}
+		p, d.head = d.head, nil
+	}
+	if d.bomEnc != nil {
+		return
+	}
+
+	for _, c := range d.checkers {
+		c.feed(p, atEOF)
+	}
+}
+
+func (d *Detector) allowsBOM(bom []byte) bool {
+	if len(d.only) == 0 {
+		return true
+	}
+	for _, e := range d.only {
+		if bm, ok := e.(ByteOrderMarker); ok && bytes.Equal(bm.BOM(), bom) {
+			return true
+		}
+	}
+	return false
+}
+
+// Detection ranks the candidates by the input fed so far. A character
+// left incomplete by the last chunk only counts against a candidate once
+// a chunk with atEOF set has been fed.
+func (d *Detector) Detection() *Detection {
+	if d.bomEnc != nil {
+		return &Detection{
+			Encoding:   d.bomEnc,
+			Confidence: 1,
+			BOM:        d.bom,
+			Candidates: []Candidate{{
+				Encoding:   d.bomEnc,
+				Confidence: 1,
+				Valid:      true,
+				Reason:     fmt.Sprintf("byte order mark % x", d.bom),
+			}},
+		}
+	}
+
+	cs := make([]Candidate, len(d.checkers))
+	for i, c := range d.checkers {
+		cs[i] = Candidate{
+			Encoding: c.Encoding,
+			Score:    c.score,
+			Valid:    c.valid(),
+			priority: c.Priority,
+			band:     d.band(c),
+			weight:   c.Weight,
+			high:     c.high,
+		}
+		if c.failed {
+			cs[i].Err = c.err
+			cs[i].Reason = fmt.Sprintf("invalid byte sequence at offset %d", c.offset)
+			if c.err != ErrInvalidEncoding {
+				cs[i].Reason = fmt.Sprintf("%v at offset %d", c.err, c.offset)
+			}
+		}
+	}
+	sort.SliceStable(cs, func(i, j int) bool {
+		a, b := cs[i], cs[j]
+		if a.Valid != b.Valid {
+			return a.Valid
+		}
+		if a.implausible() != b.implausible() {
+			return b.implausible()
+		}
+		if a.band != b.band {
+			return a.band < b.band
+		}
+		if a.evidence() != b.evidence() {
+			return a.evidence() > b.evidence()
+		}
+		if a.priority != b.priority {
+			return a.priority < b.priority
+		}
+		return a.Score > b.Score
+	})
+
+	total := 0.0
+	nValid := 0
+	for _, c := range cs {
+		if c.Valid {
+			total += c.evidence()
+			nValid++
+		}
+	}
+
+	det := &Detection{
+		Ambiguous:  nValid > 1,
+		Candidates: cs,
+	}
+	for i := range cs {
+		c := &cs[i]
+		if !c.Valid {
+			continue
+		}
+		c.Confidence = c.evidence() / total
+		switch {
+		case i == 0:
+			c.Reason = "selected"
+			det.Encoding = c.Encoding
+			det.Confidence = c.Confidence
+		case c.implausible() && !cs[0].implausible():
+			c.Reason = "valid, but decodes to unlikely text"
+		case c.band == cs[0].band:
+			c.Reason = fmt.Sprintf("valid, but %s scored higher", cs[0].Encoding)
+		default:
+			c.Reason = fmt.Sprintf("valid, but %s takes precedence", cs[0].Encoding)
+		}
+	}
+
+	return det
+}
+
+// band returns the smallest Priority of c's family.
+func (d *Detector) band(c *checker) int {
+	band := c.Priority
+	if c.Family == "" {
+		return band
+	}
+	for _, o := range d.checkers {
+		if o.Family == c.Family && o.Priority < band {
+			band = o.Priority
+		}
+	}
+	return band
+}
+
+// implausible reports whether the candidate lost more than a bit per byte
+// from 0x80 against random bytes. Input that decodes to such unlikely text
+// in one encoding is left to the encodings it looks right in, whatever
+// their priority.
+func (c *Candidate) implausible() bool {
+	return c.Score < -c.high
+}
+
+func (c *Candidate) evidence() float64 {
+	score := c.Score
+	if score < 0 {
+		score = 0
+	}
+	return c.weight * float64(1+score)
+}
+
+// Lookup returns the candidate for enc.
+func (d *Detection) Lookup(enc Encoding) (Candidate, bool) {
+	for _, c := range d.Candidates {
+		if c.Encoding.String() == enc.String() {
+			return c, true
+		}
+	}
+	return Candidate{}, false
+}
+
+// decisive reports whether the leading candidate is far enough ahead that
+// more input is unlikely to change the selection.
+func (d *Detector) decisive(minEvidence int) bool {
+	if d.sniffBOM {
+		return false
+	}
+	det := d.Detection()
+	if det.Encoding == nil || !det.Ambiguous {
+		return true
+	}
+	return det.Candidates[0].Score >= minEvidence && det.lead() >= decisiveConfidence
+}
+
+// lead returns the share of the selected candidate's evidence among the
+// valid candidates of its priority band, which more input can no longer
+// overturn once it is high.
+func (d *Detection) lead() float64 {
+	top := d.Candidates[0]
+	total := 0.0
+	for _, c := range d.Candidates {
+		if c.Valid && c.band == top.band {
+			total += c.evidence()
+		}
+	}
+	return top.evidence() / total
+}
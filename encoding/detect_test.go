@@ -0,0 +1,153 @@
+package encoding
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+const jaText = "今日は良い天気ですね。明日も晴れるでしょう。\n" +
+	"吾輩は猫である。名前はまだ無い。\n"
+
+func mustEncode(t *testing.T, enc Encoder, s string) []byte {
+	t.Helper()
+	b, err := enc.Encode(s)
+	if err != nil {
+		t.Fatalf("Encode(%q): %v", s, err)
+	}
+	return b
+}
+
+func detect(t *testing.T, b []byte, only ...Encoding) *Detection {
+	t.Helper()
+	d, err := NewDetector(only...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Feed(b, true)
+	return d.Detection()
+}
+
+func name(enc Encoding) string {
+	if enc == nil {
+		return "<nil>"
+	}
+	return enc.String()
+}
+
+func TestDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		in        []byte
+		want      Encoding
+		ambiguous bool
+	}{
+		{"ASCII", []byte("hello, world\n"), ASCII, true},
+		{"UTF-8", []byte("こんにちは、世界\n"), UTF8, true},
+		{"Shift_JIS", mustEncode(t, ShiftJIS, jaText), CP932, true},
+		{"EUC-JP", mustEncode(t, EUCJP, jaText), EUCJP, true},
+		{"ISO-2022-JP", mustEncode(t, ISO2022JP, jaText), ISO2022JP, true},
+		{"binary", []byte{0x00, 0x01, 0xff, 0xfe, 0x00}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			det := detect(t, tt.in)
+			if name(det.Encoding) != name(tt.want) {
+				t.Errorf("Encoding = %s, want %s", name(det.Encoding), name(tt.want))
+			}
+			if det.Ambiguous != tt.ambiguous {
+				t.Errorf("Ambiguous = %v, want %v", det.Ambiguous, tt.ambiguous)
+			}
+			if len(det.Candidates) != len(DefaultRegistry.Entries()) {
+				t.Errorf("got %d candidates, want one per registered encoding", len(det.Candidates))
+			}
+		})
+	}
+}
+
+func TestDetectionConfidence(t *testing.T) {
+	for _, in := range [][]byte{
+		[]byte("hello, world\n"),
+		[]byte("こんにちは、世界\n"),
+		mustEncode(t, ShiftJIS, jaText),
+		mustEncode(t, EUCJP, jaText),
+	} {
+		det := detect(t, in)
+		sum := 0.0
+		for _, c := range det.Candidates {
+			if !c.Valid && c.Confidence != 0 {
+				t.Errorf("%q: invalid %s has confidence %v", in, c.Encoding, c.Confidence)
+			}
+			sum += c.Confidence
+		}
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("%q: confidences sum to %v, want 1", in, sum)
+		}
+		// Every other valid candidate takes a share, whatever its priority.
+		if det.Ambiguous && det.Confidence >= 1 {
+			t.Errorf("%q: ambiguous %s has confidence %v", in, det.Encoding, det.Confidence)
+		}
+		if det.Confidence != det.Candidates[0].Confidence {
+			t.Errorf("%q: Confidence = %v, selected candidate has %v", in, det.Confidence, det.Candidates[0].Confidence)
+		}
+	}
+}
+
+func TestDetectionReasons(t *testing.T) {
+	det := detect(t, append([]byte("abc"), 0xff, 'd'))
+	for _, c := range det.Candidates {
+		switch {
+		case c.Encoding == det.Encoding:
+			if c.Reason != "selected" {
+				t.Errorf("%s: Reason = %q, want %q", c.Encoding, c.Reason, "selected")
+			}
+		case !c.Valid:
+			if !strings.Contains(c.Reason, " at offset ") {
+				t.Errorf("%s: Reason = %q, want the offset of the invalid input", c.Encoding, c.Reason)
+			}
+		case !strings.HasPrefix(c.Reason, "valid, but "):
+			t.Errorf("%s: Reason = %q", c.Encoding, c.Reason)
+		}
+	}
+	if c, ok := det.Lookup(UTF8); !ok || c.Reason != "invalid byte sequence at offset 3" {
+		t.Errorf("Lookup(UTF8) = %+v, %v", c, ok)
+	}
+	if _, ok := det.Lookup(UTF16); ok {
+		t.Errorf("Lookup(UTF16) found a candidate for an unregistered encoding")
+	}
+}
+
+func TestDetectionPriority(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  Encoder
+		s    string
+		want Encoding
+	}{
+		// Encodings that always score 0 keep their priority over
+		// encodings of a larger priority that score above 0.
+		{"ASCII digits", ASCII, "x0y\n", ASCII},
+		{"ASCII source", ASCII, "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n", ASCII},
+		{"short EUC-JP", EUCJP, "山田太郎", EUCJP},
+		{"short Shift_JIS", ShiftJIS, "山田太郎", CP932},
+		{"short GBK", GBK, "张伟", GBK},
+		{"short EUC-KR", EUCKR, "김철수", EUCKR},
+		{"half-width katakana", ShiftJIS, "ｱｲｳｴｵ ｶｷｸｹｺ\n", CP932},
+		{"UTF-16 without BOM", UTF16LE, "今日は良い天気ですね。\n", UTF16LE},
+		// Text that is valid, but gibberish in a multibyte encoding falls
+		// back to the single-byte encodings.
+		{"French", Windows1252, "Ça va très bien, merci. Où est la bibliothèque ?\n", Windows1252},
+		{"German", Windows1252, "Größe und Übermaß für Äpfel.\n", Windows1252},
+		{"Spanish", Windows1252, "El niño comió piña en la mañana.\n", Windows1252},
+		{"Russian", Windows1251, "Привет, как дела? Всё хорошо.\n", Windows1251},
+		{"KOI8-R", KOI8R, "Привет, как дела? Всё хорошо.\n", KOI8R},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			det := detect(t, mustEncode(t, tt.enc, tt.s))
+			if det.Encoding != tt.want {
+				t.Errorf("Encoding = %s, want %s", name(det.Encoding), name(tt.want))
+			}
+		})
+	}
+}
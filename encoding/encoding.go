@@ -15,6 +15,10 @@ const (
 
 var ErrInvalidEncoding = errors.New("invalid encoding")
 
+// ErrUnmappable is returned by Encode for characters the encoding cannot
+// represent.
+var ErrUnmappable = errors.New("rune not supported by encoding")
+
 type Encoding interface {
 	Splitter
 	Decoder
@@ -27,7 +31,43 @@ type EncodingSearcher interface {
 	EncodingSearch(p []byte, atEOF bool) (nSrc int, err error, score int)
 }
 
+// SearcherFactory is implemented by encodings that create a fresh
+// EncodingSearcher for every detection run.
+type SearcherFactory interface {
+	NewEncodingSearcher() EncodingSearcher
+}
+
+// validatorFactory is implemented by encodings whose EncodingSearcher
+// rejects input that Decode accepts, such as NUL bytes, because it is
+// unlikely in a text file. The searcher of newValidator accepts what Decode
+// accepts.
+type validatorFactory interface {
+	newValidator() EncodingSearcher
+}
+
+// newValidator returns the searcher that Validate checks input of f with.
+func newValidator(f SearcherFactory) EncodingSearcher {
+	if v, ok := f.(validatorFactory); ok {
+		return v.newValidator()
+	}
+	return f.NewEncodingSearcher()
+}
+
+// CheckEncoding runs es over the chunks in ls. If es is a SearcherFactory
+// too, as the encodings are, a fresh searcher of it runs instead.
 func CheckEncoding(ls *list.List, es EncodingSearcher) (yes bool, score int) {
+	if f, ok := es.(SearcherFactory); ok {
+		return CheckEncodingOf(ls, f)
+	}
+	return checkEncoding(ls, es)
+}
+
+// CheckEncodingOf runs a fresh searcher of f over the chunks in ls.
+func CheckEncodingOf(ls *list.List, f SearcherFactory) (yes bool, score int) {
+	return checkEncoding(ls, f.NewEncodingSearcher())
+}
+
+func checkEncoding(ls *list.List, es EncodingSearcher) (yes bool, score int) {
 	e := ls.Front()
 	var nSrc, s int
 	var err error
@@ -45,10 +85,10 @@ func CheckEncoding(ls *list.List, es EncodingSearcher) (yes bool, score int) {
 		} else {
 			nSrc, err, s = es.EncodingSearch(b, false)
 			score += s
-			if err == ErrInvalidEncoding {
-				break
-			} else if err == transform.ErrShortSrc {
+			if err == transform.ErrShortSrc {
 				prv = b[nSrc:]
+			} else if err != nil {
+				break
 			} else {
 				prv = nil
 			}
@@ -64,11 +104,22 @@ type Splitter interface {
 	Split(src []byte, atEnd bool, lines *list.List)
 }
 
+// SplitterFactory is implemented by the encodings and the splitters, whose
+// Splitter holds the state of one input, to split each input, or inputs
+// split at the same time, with a fresh one.
+type SplitterFactory interface {
+	NewSplitter() Splitter
+}
+
 type splitter struct {
 	cr      bool
 	remains []byte
 }
 
+func (sp *splitter) NewSplitter() Splitter {
+	return &splitter{}
+}
+
 func (sp *splitter) reset() {
 	sp.cr = false
 	sp.remains = []byte{}
@@ -0,0 +1,143 @@
+package encoding
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// Error is a decode or encode error at a position in a file. It unwraps to
+// its cause, so errors.Is(err, ErrInvalidEncoding) holds for decode errors
+// and errors.Is(err, ErrUnmappable) for encode errors.
+type Error struct {
+	// Offset is the byte offset of Bytes in the file.
+	Offset int64
+
+	// Line and Column locate Bytes, counted from 1. Column counts
+	// characters.
+	Line, Column int
+
+	// Bytes holds the offending bytes. For an encode error, it holds the
+	// character that could not be encoded, in UTF-8.
+	Bytes []byte
+
+	// Encoding is the name of the encoding.
+	Encoding string
+
+	// Err is the cause.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: line %d, column %d (offset %d): % x: %v",
+		e.Encoding, e.Line, e.Column, e.Offset, e.Bytes, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks that Decode of enc accepts p, a single line, with the
+// EncodingSearcher of enc in a mode that leaves out the heuristics of
+// detection. For the first invalid character it returns an *Error whose
+// Offset is relative to p and whose Line is 1. Encodings without an
+// EncodingSearcher are not checked.
+func Validate(enc Encoding, p []byte) error {
+	f, ok := enc.(SearcherFactory)
+	if !ok {
+		return nil
+	}
+	n, err, _ := newValidator(f).EncodingSearch(p, true)
+	if err == nil {
+		return nil
+	}
+
+	// The offending character ends where the searcher can read again, past
+	// the bytes its lead byte takes.
+	step, end := 1, n+1
+	if u, ok := enc.(interface{ codeUnit() int }); ok {
+		step = u.codeUnit()
+		end = n + step
+	}
+	if s, ok := enc.(interface{ span([]byte) int }); ok && n < len(p) {
+		if k := s.span(p[n:]); k > 0 {
+			end = n + k
+		}
+	}
+	for ; end < len(p) && end < n+4; end += step {
+		m, err, _ := newValidator(f).EncodingSearch(p[end:], true)
+		if err == nil || m > 0 {
+			break
+		}
+	}
+	if end > len(p) {
+		end = len(p)
+	}
+
+	return &Error{
+		Offset:   int64(n),
+		Line:     1,
+		Column:   column(enc, p[:n]),
+		Bytes:    append([]byte(nil), p[n:end]...),
+		Encoding: enc.String(),
+		Err:      err,
+	}
+}
+
+// column returns the column of the character that follows p.
+func column(d Decoder, p []byte) int {
+	s, _ := d.Decode(p)
+	return utf8.RuneCountInString(s) + 1
+}
+
+func (sp *unitSplitter) codeUnit() int {
+	return sp.size
+}
+
+// NewEncodeError returns err, which enc returned for s, as an *Error at the
+// first character of s that enc cannot encode on its own. Its Offset is
+// that of the character in the output of enc and its Line is 1.
+func NewEncodeError(enc Encoder, s string, err error) *Error {
+	e := &Error{Line: 1, Column: 1, Encoding: encoderName(enc), Err: err}
+	for i := 0; i < len(s); {
+		n := encodableLen(enc, s[i:])
+		if n > 0 {
+			e.Column += utf8.RuneCountInString(s[i : i+n])
+			i += n
+			continue
+		}
+		_, n = utf8.DecodeRuneInString(s[i:])
+		b, _ := enc.Encode(s[:i])
+		e.Offset = int64(len(trimEncodedBOM(b, enc)))
+		e.Bytes = []byte(s[i : i+n])
+		return e
+	}
+	e.Column = 1
+	return e
+}
+
+// encodableLen returns the length of the character that starts s if enc
+// encodes it on its own, or 0. A base character and a combining character
+// that JIS X 0213 has one cell for count as one character where enc encodes
+// them together.
+func encodableLen(enc Encoder, s string) int {
+	r, n := utf8.DecodeRuneInString(s)
+	if next, m := utf8.DecodeRuneInString(s[n:]); m > 0 {
+		if _, k := jis0213Index(r, next); k == 2 {
+			if _, err := enc.Encode(s[:n+m]); err == nil {
+				return n + m
+			}
+		}
+	}
+	if _, err := enc.Encode(s[:n]); err != nil {
+		return 0
+	}
+	return n
+}
+
+// encoderName returns the name of enc, if it has one.
+func encoderName(enc Encoder) string {
+	if st, ok := enc.(fmt.Stringer); ok {
+		return st.String()
+	}
+	return ""
+}
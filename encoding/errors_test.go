@@ -0,0 +1,76 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		enc    Encoding
+		in     []byte
+		offset int64 // -1 if valid
+		column int
+		bytes  []byte
+	}{
+		// Decode accepts what detection rejects as unlikely in text.
+		{"UTF-8 NUL", UTF8, []byte("a\x00b\n"), -1, 0, nil},
+		{"UTF-16 NUL", UTF16LE, []byte("a\x00\x00\x00b\x00\n\x00"), -1, 0, nil},
+		{"UTF-32 NUL", UTF32LE, []byte("a\x00\x00\x00\x00\x00\x00\x00"), -1, 0, nil},
+		{"ASCII escape", ASCII, []byte("a\x1b[0m\n"), -1, 0, nil},
+		{"Shift_JIS NEC special character", ShiftJIS, []byte("x\x87\x40y\n"), -1, 0, nil},
+		{"Shift_JIS IBM extension", ShiftJIS, []byte("\xfa\x40\n"), -1, 0, nil},
+		{"Shift_JIS 0x80", ShiftJIS, []byte("\x80\n"), -1, 0, nil},
+		{"CP932 NUL", CP932, []byte("\x00\x82\xa0\n"), -1, 0, nil},
+		{"EUC-JP NUL", EUCJP, []byte("\x00\xa4\xa2\n"), -1, 0, nil},
+		{"eucJP-ms NUL", EUCJPMS, []byte("\x00\xa4\xa2\n"), -1, 0, nil},
+		{"EUC-JIS-2004 NUL", EUCJIS2004, []byte("\x00\xa4\xa2\n"), -1, 0, nil},
+		{"ISO-2022-JP NUL", ISO2022JP, []byte("\x1b$BF|\x1b(B\x00\n"), -1, 0, nil},
+		{"ISO-2022-JP-2 NUL", ISO2022JP2, []byte("\x00\x1b$BF|\x1b(B\n"), -1, 0, nil},
+		{"GBK euro sign", GBK, []byte("\x80\n"), -1, 0, nil},
+		{"GB18030 NUL", GB18030, []byte("\x00\xd6\xd0\n"), -1, 0, nil},
+		{"ISO-8859-1 C1 control", ISO8859_1, []byte("\x85\n"), -1, 0, nil},
+
+		{"UTF-8 invalid", UTF8, []byte("ab\xffc\n"), 2, 3, []byte{0xff}},
+		{"Shift_JIS user-defined", ShiftJIS, []byte("a\xf0\x40b\n"), 1, 2, []byte{0xf0, 0x40}},
+		{"Windows-1252 unassigned", Windows1252, []byte("a\x81\n"), 1, 2, []byte{0x81}},
+		{"EUC-JP truncated", EUCJP, []byte("\xa4\xa2\xa4\n"), 2, 2, []byte{0xa4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.enc, tt.in)
+			if tt.offset < 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				if _, err := tt.enc.Decode(tt.in); err != nil {
+					t.Errorf("Decode: %v", err)
+				}
+				return
+			}
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Validate = %v, want an *Error", err)
+			}
+			if e.Offset != tt.offset || e.Column != tt.column || !bytes.Equal(e.Bytes, tt.bytes) {
+				t.Errorf("Validate = offset %d, column %d, % x; want %d, %d, % x",
+					e.Offset, e.Column, e.Bytes, tt.offset, tt.column, tt.bytes)
+			}
+			if !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("Validate = %v, want %v", err, ErrInvalidEncoding)
+			}
+		})
+	}
+}
+
+func TestValidateKeepsDetection(t *testing.T) {
+	// Detection still takes NUL bytes for binary input.
+	for _, enc := range []Encoding{UTF8, ASCII, CP932, EUCJP, EUCJIS2004, ISO2022JP2} {
+		det := detect(t, []byte("a\x00b\n"), enc)
+		if c := det.Candidates[0]; c.Valid {
+			t.Errorf("%s: NUL byte is valid for detection", enc)
+		}
+	}
+}
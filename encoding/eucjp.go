@@ -13,8 +13,6 @@ import (
 var EUCJP *eucJP = newEucJP()
 
 type eucJP struct {
-	eucJPDecoder
-
 	*splitter
 	decoder transform.Transformer
 	encoder transform.Transformer
@@ -24,27 +22,54 @@ func (eucJP) String() string {
 	return "EUC-JP"
 }
 
+func (eucJP) NewEncodingSearcher() EncodingSearcher {
+	return newEucJPDecoder()
+}
+
+// EncodingSearch checks src with a fresh searcher, so that EUCJP is an
+// EncodingSearcher itself; a run over several chunks takes the searcher of
+// NewEncodingSearcher, which scores across them.
+func (e eucJP) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	return e.NewEncodingSearcher().EncodingSearch(src, atEOF)
+}
+
+func (eucJP) newValidator() EncodingSearcher {
+	d := newEucJPDecoder()
+	d.validate = true
+	return d
+}
+
 func newEucJP() *eucJP {
 	return &eucJP{
-		eucJPDecoder: eucJPDecoder{},
-		splitter:     &splitter{},
+		splitter: &splitter{},
 	}
 }
 
-type eucJPDecoder struct{ transform.NopResetter }
+// eucJPDecoder scores the characters it validates by how typical they are
+// of Japanese text.
+type eucJPDecoder struct {
+	transform.NopResetter
+	lm       lmScorer
+	validate bool // accept NUL bytes
+}
 
-func (eucJPDecoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+func newEucJPDecoder() *eucJPDecoder {
+	return &eucJPDecoder{lm: lmScorer{m: jaModel}}
+}
+
+func (d *eucJPDecoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
 	//r, size := rune(0), 0
 	size := 0
 loop:
 	for ; nSrc < len(src); nSrc += size {
 		switch c0 := src[nSrc]; {
-		case c0 == 0x00:
+		case c0 == 0x00 && !d.validate:
 			err = ErrInvalidEncoding
 			break loop
 		case c0 < utf8.RuneSelf:
 			//r, size = rune(c0), 1
 			size = 1
+			d.lm.add(rune(c0), size)
 
 		case c0 == 0x8e:
 			if nSrc+1 >= len(src) {
@@ -57,8 +82,8 @@ loop:
 				break loop
 			}
 			//r, size = rune(c1)+(0xff61-0xa1), 2
-			score++
 			size = 2
+			d.lm.add(rune(c1)+(0xff61-0xa1), size)
 
 		case c0 == 0x8f:
 			if nSrc+2 >= len(src) {
@@ -75,15 +100,13 @@ loop:
 				err = ErrInvalidEncoding
 				break loop
 			}
-			//r, size = '\ufffd', 3
-			score++
+			r := jis0212Rune(int(c1-0xa1)*94 + int(c2-0xa1))
+			if r == RuneError {
+				err = eucJPUnassigned(c0, c1, c2)
+				break loop
+			}
 			size = 3
-			//			if i := int(c1-0xa1)*94 + int(c2-0xa1); i < len(jis0212Decode) {
-			//				r = rune(jis0212Decode[i])
-			//				if r == 0 {
-			//					r = '\ufffd'
-			//				}
-			//			}
+			d.lm.add(r, size)
 
 		case 0xa1 <= c0 && c0 <= 0xfe:
 			if nSrc+1 >= len(src) {
@@ -95,15 +118,13 @@ loop:
 				err = ErrInvalidEncoding
 				break loop
 			}
-			//r, size = '\ufffd', 2
-			score++
+			r := jis0208Rune(int(c0-0xa1)*94 + int(c1-0xa1))
+			if r == RuneError {
+				err = eucJPUnassigned(c0, c1, 0)
+				break loop
+			}
 			size = 2
-			//			if i := int(c0-0xa1)*94 + int(c1-0xa1); i < len(jis0208Decode) {
-			//				r = rune(jis0208Decode[i])
-			//				if r == 0 {
-			//					r = '\ufffd'
-			//				}
-			//			}
+			d.lm.add(r, size)
 
 		default:
 			err = ErrInvalidEncoding
@@ -119,7 +140,15 @@ loop:
 	if atEOF && err == transform.ErrShortSrc {
 		err = ErrInvalidEncoding
 	}
-	return nSrc, err, score
+	return nSrc, err, d.lm.take()
+}
+
+// eucJPUnassigned returns the error for a code EUC-JP leaves unassigned.
+func eucJPUnassigned(c0, c1, c2 byte) error {
+	if eucJPMSRune(c0, c1, c2) != RuneError {
+		return ErrMSExtension
+	}
+	return ErrInvalidEncoding
 }
 
 func (c *eucJP) getDecoder() transform.Transformer {
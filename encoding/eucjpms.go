@@ -0,0 +1,268 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"golang.org/x/text/transform"
+	"sync"
+	"unicode/utf8"
+)
+
+// EUCJPMS is eucJP-ms, the EUC-JP of Unix systems that exchange data with
+// Windows. It adds the NEC special characters (row 13), the IBM extensions
+// that JIS X 0212 lacks (0x8FF3F3-) and user-defined characters (0xF5-0xFE
+// and 0x8FF5-0x8FFE, mapped to the private use area like CP932's), so that
+// text converted from CP932 keeps every character.
+var EUCJPMS *eucJPMS = newEucJPMS()
+
+// ErrMSExtension is returned by the EUC-JP searcher for characters that
+// only eucJP-ms has.
+var ErrMSExtension = fmt.Errorf("%w: vendor extension, requires eucJP-ms", ErrInvalidEncoding)
+
+type eucJPMS struct {
+	*splitter
+}
+
+func (eucJPMS) String() string {
+	return "eucJP-ms"
+}
+
+func newEucJPMS() *eucJPMS {
+	return &eucJPMS{
+		splitter: &splitter{},
+	}
+}
+
+func (eucJPMS) NewEncodingSearcher() EncodingSearcher {
+	return newEucJPMSDecoder()
+}
+
+func (eucJPMS) newValidator() EncodingSearcher {
+	d := newEucJPMSDecoder()
+	d.validate = true
+	return d
+}
+
+const (
+	// eucJPMSUserRow is the first row of user-defined characters in both
+	// planes.
+	eucJPMSUserRow = 85
+	// eucJPMSIBM is the index of 0x8FF3F3 in the JIS X 0212 plane, where
+	// the IBM extensions missing from JIS X 0212 start.
+	eucJPMSIBM = (0xf3-0xa1)*94 + 0xf3 - 0xa1
+)
+
+// eucJPMSJIS lists the JIS X 0208 characters that eucJP-ms maps as JIS
+// does, where the EUC-JP decoder follows Microsoft.
+var eucJPMSJIS = map[int]rune{
+	0*94 + 32: 0x301c, // WAVE DASH
+	0*94 + 33: 0x2016, // DOUBLE VERTICAL LINE
+	0*94 + 60: 0x2212, // MINUS SIGN
+	0*94 + 80: 0x00a2, // CENT SIGN
+	0*94 + 81: 0x00a3, // POUND SIGN
+	1*94 + 43: 0x00ac, // NOT SIGN
+}
+
+// eucJPMSCompat maps the Microsoft forms of those characters to their
+// codes when encoding, as eucJP-ms converters do.
+var eucJPMSCompat = map[rune]int{
+	0x2225: 0*94 + 33, // PARALLEL TO
+	0xff0d: 0*94 + 60, // FULLWIDTH HYPHEN-MINUS
+	0xffe0: 0*94 + 80, // FULLWIDTH CENT SIGN
+	0xffe1: 0*94 + 81, // FULLWIDTH POUND SIGN
+	0xffe2: 1*94 + 43, // FULLWIDTH NOT SIGN
+}
+
+var (
+	eucJPMSOnce   sync.Once
+	eucJPMSDecode []rune         // JIS X 0208 plane, then JIS X 0212 plane
+	eucJPMSEncode map[rune]int32 // to eucJPMSDecode index
+)
+
+func buildEucJPMS() {
+	eucJPMSDecode = make([]rune, 2*jisCells)
+	eucJPMSEncode = make(map[rune]int32)
+	user := rune(eudcFirst)
+	for i := range eucJPMSDecode {
+		plane, j := i/jisCells, i%jisCells
+		var r rune
+		switch {
+		case j/94+1 >= eucJPMSUserRow:
+			r = user
+			user++
+		case plane == 0:
+			r = jis0208Rune(j)
+			if v, ok := eucJPMSJIS[j]; ok {
+				r = v
+			}
+		default:
+			r = jis0212Rune(j)
+		}
+		eucJPMSDecode[i] = r
+		if _, ok := eucJPMSEncode[r]; !ok && r != RuneError {
+			eucJPMSEncode[r] = int32(i)
+		}
+	}
+	for r, i := range eucJPMSCompat {
+		eucJPMSEncode[r] = int32(i)
+	}
+
+	// The IBM extensions that neither JIS X 0208 nor JIS X 0212 has follow
+	// one another in CP932 order.
+	cp932Once.Do(buildCP932)
+	next := jisCells + eucJPMSIBM
+	for i := cp932Index(0xfa, 0x40); i < len(cp932Decode); i++ {
+		r := cp932Decode[i]
+		if _, ok := eucJPMSEncode[r]; ok || r == RuneError {
+			continue
+		}
+		eucJPMSDecode[next] = r
+		eucJPMSEncode[r] = int32(next)
+		next++
+	}
+}
+
+// eucJPMSRune returns the character of a double-byte code, or of a
+// JIS X 0212 code when c0 is 0x8F.
+func eucJPMSRune(c0, c1, c2 byte) rune {
+	eucJPMSOnce.Do(buildEucJPMS)
+	i := 0
+	if c0 == 0x8f {
+		i = jisCells
+		c0, c1 = c1, c2
+	}
+	if c0 < 0xa1 || 0xfe < c0 || c1 < 0xa1 || 0xfe < c1 {
+		return RuneError
+	}
+	return eucJPMSDecode[i+int(c0-0xa1)*94+int(c1-0xa1)]
+}
+
+// eucJPMSDecoder validates eucJP-ms and scores it like eucJPDecoder.
+// With validate set, it accepts NUL bytes.
+type eucJPMSDecoder struct {
+	lm       lmScorer
+	validate bool
+}
+
+func newEucJPMSDecoder() *eucJPMSDecoder {
+	return &eucJPMSDecoder{lm: lmScorer{m: jaModel}}
+}
+
+func (d *eucJPMSDecoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	size := 0
+loop:
+	for ; nSrc < len(src); nSrc += size {
+		switch c0 := src[nSrc]; {
+		case c0 == 0x00 && !d.validate:
+			err = ErrInvalidEncoding
+			break loop
+
+		case c0 < utf8.RuneSelf:
+			size = 1
+			d.lm.add(rune(c0), size)
+
+		case c0 == 0x8e:
+			if nSrc+1 >= len(src) {
+				err = transform.ErrShortSrc
+				break loop
+			}
+			c1 := src[nSrc+1]
+			if c1 < 0xa1 || 0xdf < c1 {
+				err = ErrInvalidEncoding
+				break loop
+			}
+			size = 2
+			d.lm.add(rune(c1)+(0xff61-0xa1), size)
+
+		case c0 == 0x8f:
+			if nSrc+2 >= len(src) {
+				err = transform.ErrShortSrc
+				break loop
+			}
+			r := eucJPMSRune(c0, src[nSrc+1], src[nSrc+2])
+			if r == RuneError {
+				err = ErrInvalidEncoding
+				break loop
+			}
+			size = 3
+			d.lm.add(r, size)
+
+		case 0xa1 <= c0 && c0 <= 0xfe:
+			if nSrc+1 >= len(src) {
+				err = transform.ErrShortSrc
+				break loop
+			}
+			r := eucJPMSRune(c0, src[nSrc+1], 0)
+			if r == RuneError {
+				err = ErrInvalidEncoding
+				break loop
+			}
+			size = 2
+			d.lm.add(r, size)
+
+		default:
+			err = ErrInvalidEncoding
+			break loop
+		}
+	}
+	if atEOF && err == transform.ErrShortSrc {
+		err = ErrInvalidEncoding
+	}
+	return nSrc, err, d.lm.take()
+}
+
+// Decode reads unassigned codes, and bytes that start no character, as
+// RuneError, and returns ErrInvalidEncoding with the text.
+func (c *eucJPMS) Decode(b []byte) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	for i := 0; i < len(b); {
+		c0 := b[i]
+		r := RuneError
+		switch {
+		case c0 < utf8.RuneSelf:
+			r = rune(c0)
+			i++
+		case c0 == 0x8e && i+1 < len(b) && 0xa1 <= b[i+1] && b[i+1] <= 0xdf:
+			r = rune(b[i+1]) + (0xff61 - 0xa1)
+			i += 2
+		case c0 == 0x8f && i+2 < len(b):
+			r = eucJPMSRune(c0, b[i+1], b[i+2])
+			i += 3
+		case 0xa1 <= c0 && c0 <= 0xfe && i+1 < len(b):
+			r = eucJPMSRune(c0, b[i+1], 0)
+			i += 2
+		default:
+			i++
+		}
+		if r == RuneError {
+			err = ErrInvalidEncoding
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String(), err
+}
+
+func (c *eucJPMS) Encode(s string) ([]byte, error) {
+	eucJPMSOnce.Do(buildEucJPMS)
+	ret := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r < utf8.RuneSelf:
+			ret = append(ret, byte(r))
+		case 0xff61 <= r && r < 0xffa0:
+			ret = append(ret, 0x8e, byte(r-(0xff61-0xa1)))
+		default:
+			i, ok := eucJPMSEncode[r]
+			if !ok {
+				return nil, ErrUnmappable
+			}
+			if i >= jisCells {
+				ret = append(ret, 0x8f)
+				i -= jisCells
+			}
+			ret = append(ret, byte(0xa1+i/94), byte(0xa1+i%94))
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,64 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEUCJPMS(t *testing.T) {
+	tests := []struct {
+		s string
+		b []byte
+	}{
+		{"日本語ｱ", []byte("\xc6\xfc\xcb\xdc\xb8\xec\x8e\xb1")},
+		{"丂", []byte("\x8f\xb0\xa1")},                        // JIS X 0212
+		{"①", []byte("\xad\xa1")},                            // NEC special characters
+		{"ⅰ髙", []byte("\x8f\xf3\xf3\x8f\xf4\xef")},           // IBM extensions
+		{"〜‖−¬", []byte("\xa1\xc1\xa1\xc2\xa1\xdd\xa2\xcc")}, // as JIS maps them
+		{"\ue000\ue3ac", []byte("\xf5\xa1\x8f\xf5\xa1")},     // user-defined characters
+	}
+	for _, tt := range tests {
+		if b := mustEncode(t, EUCJPMS, tt.s); !bytes.Equal(b, tt.b) {
+			t.Errorf("Encode(%q) = % x, want % x", tt.s, b, tt.b)
+		}
+		if s, err := EUCJPMS.Decode(tt.b); s != tt.s || err != nil {
+			t.Errorf("Decode(% x) = %q, %v; want %q", tt.b, s, err, tt.s)
+		}
+	}
+
+	// The Microsoft forms encode as the JIS ones.
+	if b := mustEncode(t, EUCJPMS, "∥－￠￡￢"); !bytes.Equal(b, []byte("\xa1\xc2\xa1\xdd\xa1\xf1\xa1\xf2\xa2\xcc")) {
+		t.Errorf("Encode of the Microsoft forms = % x", b)
+	}
+}
+
+func TestEUCJPMSFromCP932(t *testing.T) {
+	in := mustEncode(t, CP932, "①㈱ⅰ纊～∥－￢髙ｱ日本語")
+	s, err := CP932.Decode(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := EUCJPMS.Decode(mustEncode(t, EUCJPMS, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if back := mustEncode(t, CP932, u); !bytes.Equal(back, in) {
+		t.Errorf("CP932 -> eucJP-ms -> CP932 = % x, want % x", back, in)
+	}
+}
+
+func TestDetectionNeedsEUCJPMS(t *testing.T) {
+	in := mustEncode(t, EUCJPMS, "ⅰⅱⅲの項目は髙橋さんが確認しました。①から③まで\n")
+	det := detect(t, in)
+	if det.Encoding != EUCJPMS {
+		t.Errorf("eucJP-ms detected as %s", name(det.Encoding))
+	}
+	if c, _ := det.Lookup(EUCJP); c.Valid || !errors.Is(c.Err, ErrMSExtension) {
+		t.Errorf("EUC-JP candidate has Valid %v, Err %v; want ErrMSExtension", c.Valid, c.Err)
+	}
+
+	if det := detect(t, mustEncode(t, EUCJP, jaText)); det.Encoding != EUCJP {
+		t.Errorf("EUC-JP detected as %s", name(det.Encoding))
+	}
+}
@@ -0,0 +1,19 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/korean"
+	"golang.org/x/text/transform"
+)
+
+// EUCKR is EUC-KR of Korean as extended by the Unified Hangul Code
+// (CP949), which has all 11,172 Hangul syllables.
+var EUCKR *dbcsEncoding = newDBCSEncoding("EUC-KR", eucKRTable, nil, koModel,
+	func() transform.Transformer { return korean.EUCKR.NewDecoder() },
+	func() transform.Transformer { return korean.EUCKR.NewEncoder() })
+
+var eucKRTable = &dbcsTable{
+	newDecoder: func() transform.Transformer {
+		return korean.EUCKR.NewDecoder()
+	},
+	core: euc94,
+}
@@ -0,0 +1,148 @@
+package encoding
+
+import (
+	"bufio"
+	"fmt"
+	"golang.org/x/text/unicode/norm"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Fallback maps characters an encoder cannot encode to text it can, such as
+// "ⅰ" to "i" or "①" to "(1)". EncodeWith consults it before its policy.
+type Fallback struct {
+	m map[rune]string
+
+	// Decompose falls back to the compatibility decomposition of a
+	// character, without its combining marks if need be, so that "①"
+	// becomes "1" and "ŵ" becomes "w".
+	Decompose bool
+}
+
+// NewFallback returns an empty Fallback.
+func NewFallback() *Fallback {
+	return &Fallback{m: map[rune]string{}}
+}
+
+// Add maps r to s.
+func (f *Fallback) Add(r rune, s string) {
+	f.m[r] = s
+}
+
+// LoadFallback reads a table with one mapping per line, the character and
+// its replacement separated by "→" or by white space, as in
+//
+//	ⅰ → i
+//	①	(1)
+//	U+301C U+FF5E
+//
+// Characters may be written as U+XXXX. A replacement may be empty, dropping
+// the character. Blank lines and lines starting with "#" are ignored.
+func LoadFallback(r io.Reader) (*Fallback, error) {
+	f := NewFallback()
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var from, to string
+		if i := strings.Index(line, "→"); i >= 0 {
+			from, to = line[:i], line[i+len("→"):]
+		} else if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			from, to = line[:i], line[i:]
+		} else {
+			from = line
+		}
+		from, err := unescapeFallback(strings.TrimSpace(from))
+		if err == nil && utf8.RuneCountInString(from) != 1 {
+			err = fmt.Errorf("%q is not a single character", from)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("fallback: line %d: %v", n, err)
+		}
+		to, err = unescapeFallback(strings.TrimSpace(to))
+		if err != nil {
+			return nil, fmt.Errorf("fallback: line %d: %v", n, err)
+		}
+		r, _ := utf8.DecodeRuneInString(from)
+		f.Add(r, to)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// LoadFallbackFile reads a table as LoadFallback from the named file.
+func LoadFallbackFile(name string) (*Fallback, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return LoadFallback(file)
+}
+
+// unescapeFallback replaces the U+XXXX forms in s.
+func unescapeFallback(s string) (string, error) {
+	var buf strings.Builder
+	for {
+		i := strings.Index(s, "U+")
+		if i < 0 {
+			buf.WriteString(s)
+			return buf.String(), nil
+		}
+		buf.WriteString(s[:i])
+		s = s[i+2:]
+		n := 0
+		for n < len(s) && n < 6 && strings.IndexByte("0123456789ABCDEFabcdef", s[n]) >= 0 {
+			n++
+		}
+		if n < 4 {
+			return "", fmt.Errorf("bad character code U+%s", s[:n])
+		}
+		v, _ := strconv.ParseUint(s[:n], 16, 32)
+		if !utf8.ValidRune(rune(v)) {
+			return "", fmt.Errorf("bad character code U+%s", s[:n])
+		}
+		buf.WriteRune(rune(v))
+		s = s[n:]
+	}
+}
+
+// lookup returns the replacement of r that enc can encode.
+func (f *Fallback) lookup(enc Encoder, r rune) (string, bool) {
+	if f == nil {
+		return "", false
+	}
+	if s, ok := f.m[r]; ok {
+		if _, err := enc.Encode(s); err == nil {
+			return s, true
+		}
+	}
+	if !f.Decompose {
+		return "", false
+	}
+	s := norm.NFKD.String(string(r))
+	if s == string(r) {
+		return "", false
+	}
+	if _, err := enc.Encode(s); err == nil {
+		return s, true
+	}
+	s = strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, s)
+	if _, err := enc.Encode(s); err == nil && s != "" {
+		return s, true
+	}
+	return "", false
+}
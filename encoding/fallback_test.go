@@ -0,0 +1,113 @@
+package encoding
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadFallback(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want map[rune]string
+		fail bool
+	}{
+		{"arrow", "ⅰ → i\n① →(1)\n", map[rune]string{'ⅰ': "i", '①': "(1)"}, false},
+		{"white space", "ⅱ\tii\n〜 ～\n", map[rune]string{'ⅱ': "ii", '〜': "～"}, false},
+		{"code points", "U+301C U+FF5E\nU+1F600 → :U+0029\n", map[rune]string{'〜': "～", '😀': ":)"}, false},
+		{"empty replacement", "U+200B\n", map[rune]string{'\u200b': ""}, false},
+		{"comments and blank lines", "# table\n\n  ⅰ → i  \n", map[rune]string{'ⅰ': "i"}, false},
+		{"not a single character", "ab → c\n", nil, true},
+		{"bad code point", "U+12 → x\n", nil, true},
+		{"beyond Unicode", "U+110000 → x\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := LoadFallback(strings.NewReader(tt.in))
+			if tt.fail {
+				if err == nil {
+					t.Errorf("LoadFallback did not fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(f.m) != len(tt.want) {
+				t.Errorf("got %d mappings, want %d", len(f.m), len(tt.want))
+			}
+			for r, s := range tt.want {
+				if got, ok := f.m[r]; !ok || got != s {
+					t.Errorf("%q maps to %q, %v; want %q", r, got, ok, s)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadFallbackFile(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "fallback.txt")
+	if err := os.WriteFile(name, []byte("# Roman numerals\nⅰ → i\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadFallbackFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := EncodeWith(ASCII, "ⅰ.", Options{Fallback: f}); err != nil || string(b) != "i." {
+		t.Errorf("EncodeWith = %q, %v; want %q", b, err, "i.")
+	}
+	if _, err := LoadFallbackFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Errorf("LoadFallbackFile of a missing file did not fail")
+	}
+}
+
+func TestEncodeWithFallback(t *testing.T) {
+	f, err := LoadFallback(strings.NewReader("ⅰ → i\n① → (1)\n〜 → ～\n😀 → ☺\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decompose := NewFallback()
+	decompose.Decompose = true
+	tests := []struct {
+		name string
+		enc  Encoder
+		f    *Fallback
+		in   string
+		want string
+		fail bool
+	}{
+		{"table", ASCII, f, "ⅰ and ①", "i and (1)", false},
+		{"wave dash", ShiftJIS, f, "〜", "\x81\x60", false},
+		{"encodable as it is", ShiftJIS, f, "①", "\x87\x40", false},
+		{"replacement not encodable", ASCII, f, "😀", "", true},
+		{"not in the table", ASCII, f, "ⅱ", "", true},
+		{"compatibility decomposition", ASCII, decompose, "①ｘ", "1x", false},
+		{"combining marks dropped", ASCII, decompose, "ŵé", "we", false},
+		{"no decomposition", ASCII, decompose, "日", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := EncodeWith(tt.enc, tt.in, Options{Fallback: tt.f})
+			if tt.fail {
+				var e *Error
+				if !errors.As(err, &e) {
+					t.Errorf("EncodeWith = %q, %v; want an *Error", b, err)
+				}
+				return
+			}
+			if err != nil || string(b) != tt.want {
+				t.Errorf("EncodeWith = % x, %v; want % x", b, err, tt.want)
+			}
+		})
+	}
+
+	// The policy applies to what the table leaves.
+	b, err := EncodeWith(ASCII, "ⅰⅱ", Options{Policy: Replace, Fallback: f})
+	if err != nil || string(b) != "i?" {
+		t.Errorf("EncodeWith with Replace = %q, %v; want %q", b, err, "i?")
+	}
+}
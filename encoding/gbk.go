@@ -0,0 +1,39 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+	"unicode/utf8"
+)
+
+// GBK is the GBK code page (CP936) of simplified Chinese, a superset of
+// GB 2312.
+var GBK *dbcsEncoding = newDBCSEncoding("GBK", gbkTable, nil, zhHansModel,
+	func() transform.Transformer { return simplifiedchinese.GBK.NewDecoder() },
+	func() transform.Transformer { return simplifiedchinese.GBK.NewEncoder() })
+
+// GB18030 extends GBK with four-byte sequences that cover all of Unicode.
+var GB18030 *dbcsEncoding = newDBCSEncoding("GB18030", gbkTable, gb18030Rune, zhHansModel,
+	func() transform.Transformer { return simplifiedchinese.GB18030.NewDecoder() },
+	func() transform.Transformer { return simplifiedchinese.GB18030.NewEncoder() })
+
+var gbkTable = &dbcsTable{
+	newDecoder: func() transform.Transformer {
+		return simplifiedchinese.GBK.NewDecoder()
+	},
+	core: euc94,
+	euro: true,
+}
+
+// gb18030Rune returns the character of a four-byte sequence.
+func gb18030Rune(b []byte) rune {
+	if b[2] < 0x81 || 0xfe < b[2] || b[3] < '0' || '9' < b[3] {
+		return RuneError
+	}
+	dst, _, err := transform.Bytes(simplifiedchinese.GB18030.NewDecoder(), b)
+	r, size := utf8.DecodeRune(dst)
+	if err != nil || size != len(dst) {
+		return RuneError
+	}
+	return r
+}
@@ -23,7 +23,6 @@ const asciiEsc = 0x1b
 type iso2022JPDecorder int
 
 type iso2022JPEncoding struct {
-	*iso2022JPDecorder
 	*splitter
 
 	decoder transform.Transformer
@@ -34,10 +33,26 @@ func (iso2022JPEncoding) String() string {
 	return "ISO2022"
 }
 
+func (iso2022JPEncoding) NewEncodingSearcher() EncodingSearcher {
+	return new(iso2022JPDecorder)
+}
+
+// EncodingSearch checks src with a fresh searcher, so that ISO2022JP is an
+// EncodingSearcher itself; a run over several chunks takes the searcher of
+// NewEncodingSearcher, which keeps the shift state across them.
+func (e iso2022JPEncoding) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	return e.NewEncodingSearcher().EncodingSearch(src, atEOF)
+}
+
+// newValidator reads the sets of the EncodingSearcher, and accepts NUL
+// bytes as the x/text decoder does.
+func (iso2022JPEncoding) newValidator() EncodingSearcher {
+	return iso2022JPLines.newDecoder()
+}
+
 func newIso2022JPEncoding() *iso2022JPEncoding {
 	return &iso2022JPEncoding{
-		iso2022JPDecorder: new(iso2022JPDecorder),
-		splitter:          &splitter{},
+		splitter: &splitter{},
 	}
 }
 
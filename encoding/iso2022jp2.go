@@ -0,0 +1,420 @@
+package encoding
+
+import (
+	"bytes"
+	"code.google.com/p/go.text/encoding/charmap"
+	"golang.org/x/text/transform"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// ISO2022JP2 is ISO-2022-JP-2 (RFC 1554), ISO-2022-JP extended with
+// JIS X 0212, GB 2312, KS X 1001 and, through single shifts, the upper
+// halves of ISO-8859-1 and ISO-8859-7.
+var ISO2022JP2 *iso2022Encoding = newIso2022Encoding("ISO-2022-JP-2", iso2022JP2Escapes,
+	[]iso2022Set{csASCII, csJIS0208, csJIS0212, csGB2312, csKSC5601, csLatin1, csGreek})
+
+// ISO2022JP2004 is ISO-2022-JP-2004, ISO-2022-JP with both planes of
+// JIS X 0213. It also reads ISO-2022-JP-3.
+var ISO2022JP2004 *iso2022Encoding = newIso2022Encoding("ISO-2022-JP-2004", iso2022JP2004Escapes,
+	[]iso2022Set{csASCII, csJIS0213P1, csJIS0213P2})
+
+// iso2022Set is a character set that an escape sequence designates.
+type iso2022Set int
+
+const (
+	csASCII iso2022Set = iota
+	csRoman
+	csKatakana
+	csJIS0208
+	csJIS0212
+	csGB2312
+	csKSC5601
+	csJIS0213P1
+	csJIS0213P2
+
+	// 96-character sets, designated to G2 and invoked by csSS2
+	csLatin1
+	csGreek
+
+	// csSS2 is not a set but the single shift ESC N.
+	csSS2
+	csNone iso2022Set = -1
+)
+
+func (s iso2022Set) wide() bool {
+	return csJIS0208 <= s && s <= csJIS0213P2
+}
+
+// iso2022Designations are the escape sequences written for each set.
+var iso2022Designations = [...]string{
+	csASCII:     "\x1b(B",
+	csRoman:     "\x1b(J",
+	csKatakana:  "\x1b(I",
+	csJIS0208:   "\x1b$B",
+	csJIS0212:   "\x1b$(D",
+	csGB2312:    "\x1b$A",
+	csKSC5601:   "\x1b$(C",
+	csJIS0213P1: "\x1b$(Q",
+	csJIS0213P2: "\x1b$(P",
+	csLatin1:    "\x1b.A",
+	csGreek:     "\x1b.F",
+	csSS2:       "\x1bN",
+}
+
+var iso2022JP2Escapes = map[string]iso2022Set{
+	"\x1b(B":  csASCII,
+	"\x1b(J":  csRoman,
+	"\x1b$@":  csJIS0208,
+	"\x1b$B":  csJIS0208,
+	"\x1b$(D": csJIS0212,
+	"\x1b$A":  csGB2312,
+	"\x1b$(C": csKSC5601,
+	"\x1b.A":  csLatin1,
+	"\x1b.F":  csGreek,
+	"\x1bN":   csSS2,
+}
+
+var iso2022JP2004Escapes = map[string]iso2022Set{
+	"\x1b(B":  csASCII,
+	"\x1b$B":  csJIS0208,
+	"\x1b$(O": csJIS0213P1, // ISO-2022-JP-3
+	"\x1b$(Q": csJIS0213P1,
+	"\x1b$(P": csJIS0213P2,
+}
+
+var (
+	greekOnce sync.Once
+	greekHigh [96]rune // ISO-8859-7 0xA0-0xFF
+)
+
+func greekRune(c byte) rune {
+	greekOnce.Do(func() {
+		d := charmap.ISO8859_7.NewDecoder()
+		for i := range greekHigh {
+			d.Reset()
+			dst, _, err := transform.Bytes(d, []byte{byte(0xa0 + i)})
+			r, size := utf8.DecodeRune(dst)
+			if err != nil || size != len(dst) {
+				r = RuneError
+			}
+			greekHigh[i] = r
+		}
+	})
+	return greekHigh[c-0x20]
+}
+
+type iso2022Encoding struct {
+	*splitter
+
+	name    string
+	escapes map[string]iso2022Set
+	order   []iso2022Set
+}
+
+func (e iso2022Encoding) String() string {
+	return e.name
+}
+
+func newIso2022Encoding(name string, escapes map[string]iso2022Set, order []iso2022Set) *iso2022Encoding {
+	return &iso2022Encoding{
+		splitter: &splitter{},
+		name:     name,
+		escapes:  escapes,
+		order:    order,
+	}
+}
+
+func (e *iso2022Encoding) NewEncodingSearcher() EncodingSearcher {
+	d := e.newDecoder()
+	d.detect = true
+	return d
+}
+
+func (e *iso2022Encoding) newValidator() EncodingSearcher {
+	return e.newDecoder()
+}
+
+func (e *iso2022Encoding) newDecoder() *iso2022Decoder {
+	return &iso2022Decoder{e: e, g0: csASCII, g2: csNone}
+}
+
+// iso2022Decoder reads an ISO-2022 encoding, keeping the designations in
+// effect. With carry set, they also stay in effect past the end of a line.
+// With detect set, it rejects NUL bytes, which text files lack.
+type iso2022Decoder struct {
+	e      *iso2022Encoding
+	g0, g2 iso2022Set
+	carry  bool
+	detect bool
+}
+
+func (d *iso2022Decoder) Reset() {
+	d.g0, d.g2 = csASCII, csNone
+}
+
+// escape matches the escape sequence at the start of p.
+func (d *iso2022Decoder) escape(p []byte) (set iso2022Set, n int, err error) {
+	for seq, set := range d.e.escapes {
+		if len(seq) <= len(p) && string(p[:len(seq)]) == seq {
+			return set, len(seq), nil
+		}
+	}
+	for seq := range d.e.escapes {
+		if len(p) < len(seq) && seq[:len(p)] == string(p) {
+			return csNone, 0, transform.ErrShortSrc
+		}
+	}
+	return csNone, 0, ErrInvalidEncoding
+}
+
+// next reads the character at the start of p, applying escape sequences
+// before it. It returns the character, a combining character that follows
+// it or 0, and the bytes read; the character is -1 if p holds nothing but
+// escape sequences.
+func (d *iso2022Decoder) next(p []byte) (r, comb rune, n int, err error) {
+	for n < len(p) && p[n] == asciiEsc {
+		set, size, err := d.escape(p[n:])
+		if err != nil {
+			return RuneError, 0, n, err
+		}
+		if set == csSS2 {
+			// ESC N invokes one character of G2.
+			if d.g2 == csNone {
+				return RuneError, 0, n, ErrInvalidEncoding
+			}
+			if len(p) <= n+size {
+				return RuneError, 0, n, transform.ErrShortSrc
+			}
+			c := p[n+size]
+			if c < 0x20 || 0x80 <= c {
+				return RuneError, 0, n, ErrInvalidEncoding
+			}
+			r = rune(c) + 0x80
+			if d.g2 == csGreek {
+				r = greekRune(c)
+			}
+			if r == RuneError {
+				return RuneError, 0, n, ErrInvalidEncoding
+			}
+			return r, 0, n + size + 1, nil
+		}
+		if set >= csLatin1 {
+			d.g2 = set
+		} else {
+			d.g0 = set
+		}
+		n += size
+	}
+	if len(p) <= n {
+		return -1, 0, n, nil
+	}
+
+	c0 := p[n]
+	switch {
+	case c0 == 0x00 && d.detect, utf8.RuneSelf <= c0:
+		return RuneError, 0, n, ErrInvalidEncoding
+
+	case (c0 == LE || c0 == CR) && !d.carry:
+		// A line ends in ASCII, and designates G2 again after it.
+		if d.g0 != csRoman {
+			d.g0 = csASCII
+		}
+		d.g2 = csNone
+		return rune(c0), 0, n + 1, nil
+
+	case c0 <= 0x20 || c0 == 0x7f:
+		return rune(c0), 0, n + 1, nil
+	}
+
+	switch d.g0 {
+	case csASCII:
+		r = rune(c0)
+	case csRoman:
+		switch c0 {
+		case 0x5c:
+			r = 0xa5
+		case 0x7e:
+			r = 0x203e
+		default:
+			r = rune(c0)
+		}
+	case csKatakana:
+		if 0x60 <= c0 {
+			return RuneError, 0, n, ErrInvalidEncoding
+		}
+		r = rune(c0) + (0xff61 - 0x21)
+	default:
+		if len(p) <= n+1 {
+			return RuneError, 0, n, transform.ErrShortSrc
+		}
+		c1 := p[n+1]
+		if c1 <= 0x20 || 0x7f <= c1 {
+			return RuneError, 0, n, ErrInvalidEncoding
+		}
+		i := int(c0-0x21)*94 + int(c1-0x21)
+		switch d.g0 {
+		case csJIS0208:
+			r = jis0208Rune(i)
+		case csJIS0212:
+			r = jis0212Rune(i)
+		case csGB2312:
+			r = gb2312Cells.rune(i)
+		case csKSC5601:
+			r = ksc5601Cells.rune(i)
+		case csJIS0213P1:
+			r, comb = jis0213Rune(i)
+		case csJIS0213P2:
+			r, comb = jis0213Rune(jisCells + i)
+		}
+		if r == RuneError {
+			return RuneError, 0, n, ErrInvalidEncoding
+		}
+		return r, comb, n + 2, nil
+	}
+	return r, 0, n + 1, nil
+}
+
+// EncodingSearch counts escape sequences and the characters of the sets
+// they designate.
+func (d *iso2022Decoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	for nSrc < len(src) {
+		r, _, size, e := d.next(src[nSrc:])
+		if size > 0 && (src[nSrc] == asciiEsc || r >= utf8.RuneSelf) {
+			score++
+		}
+		if e != nil {
+			err = e
+			if e != transform.ErrShortSrc {
+				nSrc += size
+			}
+			break
+		}
+		nSrc += size
+	}
+	if atEOF && err == transform.ErrShortSrc {
+		err = ErrInvalidEncoding
+	}
+	return nSrc, err, score
+}
+
+func (e *iso2022Encoding) Decode(b []byte) (string, error) {
+	return e.newDecoder().decode(b)
+}
+
+// decode reads each character that the sets in effect do not hold as
+// RuneError, and returns ErrInvalidEncoding with the text.
+func (d *iso2022Decoder) decode(b []byte) (string, error) {
+	var buf strings.Builder
+	var err error
+	for len(b) > 0 {
+		r, comb, size, nerr := d.next(b)
+		if nerr != nil {
+			buf.WriteRune(RuneError)
+			err = ErrInvalidEncoding
+			b = b[size+1:]
+			continue
+		}
+		if r >= 0 {
+			buf.WriteRune(r)
+		}
+		if comb != 0 {
+			buf.WriteRune(comb)
+		}
+		b = b[size:]
+	}
+	return buf.String(), err
+}
+
+// lookup finds the code of r in set, taking next along if they make one
+// character. It returns the code, its length and the characters consumed.
+func (e *iso2022Encoding) lookup(set iso2022Set, r, next rune) (code [2]byte, size, n int) {
+	i := -1
+	n = 1
+	switch set {
+	case csASCII:
+		if r < utf8.RuneSelf && r != asciiEsc {
+			return [2]byte{byte(r)}, 1, 1
+		}
+	case csLatin1:
+		if 0xa0 <= r && r <= 0xff {
+			return [2]byte{byte(r - 0x80)}, 1, 1
+		}
+	case csGreek:
+		for c := byte(0x20); c < 0x80; c++ {
+			if greekRune(c) == r {
+				return [2]byte{c}, 1, 1
+			}
+		}
+	case csJIS0208:
+		// The EUC-JP table also has the NEC and IBM extensions of CP932 in
+		// rows 13 and 89-92, which JIS X 0208 leaves unassigned. They are
+		// read, but not written.
+		i = jis0208Cells.index(r)
+		if row := i/94 + 1; 8 < row && row < 16 || 84 < row {
+			i = -1
+		}
+	case csJIS0212:
+		i = jis0212Cells.index(r)
+	case csGB2312:
+		i = gb2312Cells.index(r)
+	case csKSC5601:
+		i = ksc5601Cells.index(r)
+	case csJIS0213P1, csJIS0213P2:
+		i, n = jis0213Index(r, next)
+		if (i >= jisCells) != (set == csJIS0213P2) {
+			i = -1
+		}
+		i %= jisCells
+	}
+	if i < 0 {
+		return code, 0, 0
+	}
+	return [2]byte{byte(0x21 + i/94), byte(0x21 + i%94)}, 2, n
+}
+
+// Encode designates the first set in the encoding's order that has each
+// character, and ends in ASCII.
+func (e *iso2022Encoding) Encode(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	g0, g2 := csASCII, csNone
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r, next := rs[i], rune(0)
+		if i+1 < len(rs) {
+			next = rs[i+1]
+		}
+		var code [2]byte
+		size, n := 0, 0
+		set := csNone
+		for _, set = range e.order {
+			if code, size, n = e.lookup(set, r, next); n > 0 {
+				break
+			}
+		}
+		if n == 0 {
+			return nil, ErrUnmappable
+		}
+		switch {
+		case set >= csLatin1:
+			if g2 != set {
+				buf.WriteString(iso2022Designations[set])
+				g2 = set
+			}
+			buf.WriteString(iso2022Designations[csSS2])
+		case g0 != set:
+			buf.WriteString(iso2022Designations[set])
+			g0 = set
+		}
+		buf.Write(code[:size])
+		if r == rune(LE) || r == rune(CR) {
+			g2 = csNone
+		}
+		i += n
+	}
+	if g0 != csASCII {
+		buf.WriteString(iso2022Designations[csASCII])
+	}
+	return buf.Bytes(), nil
+}
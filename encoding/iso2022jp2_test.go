@@ -0,0 +1,106 @@
+package encoding
+
+import (
+	"testing"
+)
+
+func TestISO2022JP2(t *testing.T) {
+	tests := []struct {
+		enc *iso2022Encoding
+		s   string
+		b   string
+	}{
+		{ISO2022JP2, "a\nb", "a\nb"},
+		{ISO2022JP2, "日本語", "\x1b$BF|K\\8l\x1b(B"},
+		{ISO2022JP2, "丂", "\x1b$(D0!\x1b(B"},
+		{ISO2022JP2, "简体", "\x1b$A<r\x1b$BBN\x1b(B"},
+		{ISO2022JP2, "한국어", "\x1b$(CGQ19>n\x1b(B"},
+		{ISO2022JP2, "é ñ", "\x1b$(D+1\x1b(B \x1b$(D+P\x1b(B"},
+		{ISO2022JP2004, "日本", "\x1b$(QF|K\\\x1b(B"},
+		{ISO2022JP2004, "俱𠀋", "\x1b$(Q.!.\"\x1b(B"},
+		{ISO2022JP2004, "𠂉丂", "\x1b$(P!!!\"\x1b(B"},
+		{ISO2022JP2004, "か゚ㇷ゚", "\x1b$(Q$w&x\x1b(B"}, // base and combining character in one cell
+	}
+	for _, tt := range tests {
+		if b := mustEncode(t, tt.enc, tt.s); string(b) != tt.b {
+			t.Errorf("%s: Encode(%q) = %q, want %q", tt.enc, tt.s, b, tt.b)
+		}
+		if s, err := tt.enc.Decode([]byte(tt.b)); s != tt.s || err != nil {
+			t.Errorf("%s: Decode(%q) = %q, %v; want %q", tt.enc, tt.b, s, err, tt.s)
+		}
+	}
+}
+
+func TestISO2022JP2Decode(t *testing.T) {
+	tests := []struct {
+		enc  *iso2022Encoding
+		b    string
+		want string
+	}{
+		{ISO2022JP2, "\x1b.A\x1bNi", "é"},       // ISO-8859-1 by single shift
+		{ISO2022JP2, "\x1b.F\x1bNa", "α"},       // ISO-8859-7 by single shift
+		{ISO2022JP2, "\x1b$@F|\x1b(B", "日"},     // JIS C 6226
+		{ISO2022JP2004, "\x1b$(O.!\x1b(B", "俱"}, // ISO-2022-JP-3
+		{ISO2022JP2004, "\x1b$BF|\x1b(B", "日"},  // JIS X 0208
+	}
+	for _, tt := range tests {
+		if s, err := tt.enc.Decode([]byte(tt.b)); s != tt.want || err != nil {
+			t.Errorf("%s: Decode(%q) = %q, %v; want %q", tt.enc, tt.b, s, err, tt.want)
+		}
+	}
+}
+
+func TestISO2022JP2Detection(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Encoding
+	}{
+		{"日本語のテキスト\n", ISO2022JP},
+		{"日本語 中文简体 한국어\n", ISO2022JP2},
+		{"𠂉田か゚さん\n", ISO2022JP2004},
+	}
+	for _, tt := range tests {
+		enc := tt.want.(Encoder)
+		if det := detect(t, mustEncode(t, enc, tt.s)); det.Encoding != tt.want {
+			t.Errorf("%q in %s detected as %s", tt.s, tt.want, name(det.Encoding))
+		}
+	}
+}
+
+func TestISO2022JP2Sets(t *testing.T) {
+	// Characters that only the vendor tables have are written in the sets
+	// that really have them, or not at all.
+	tests := []struct {
+		s    string
+		want string // empty if unmappable
+	}{
+		{"①", "\x1b$A\"Y\x1b(B"},  // NEC row 13 of CP932
+		{"ⅰ", "\x1b$(C%!\x1b(B"},  // IBM extension of CP932, A2A1 of GBK
+		{"≒", "\x1b$B\"b\x1b(B"},  // in row 2 as well as row 13
+		{"€", "\x1b$(C\"f\x1b(B"}, // A2E3 of GBK
+		{"︵", ""},                 // vertical form of GBK
+	}
+	for _, tt := range tests {
+		b, err := ISO2022JP2.Encode(tt.s)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("Encode(%q) = %q, want an error", tt.s, b)
+			}
+			continue
+		}
+		if err != nil || string(b) != tt.want {
+			t.Errorf("Encode(%q) = %q, %v; want %q", tt.s, b, err, tt.want)
+		}
+	}
+
+	// GB 2312 has 682 symbols and 6763 hanzi.
+	n := 0
+	for i := 0; i < jisCells; i++ {
+		if gb2312Cells.rune(i) != RuneError {
+			n++
+		}
+	}
+	if n != 7445 {
+		t.Errorf("GB 2312 has %d characters, want 7445", n)
+	}
+}
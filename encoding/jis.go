@@ -0,0 +1,190 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/japanese"
+	"code.google.com/p/go.text/encoding/korean"
+	"code.google.com/p/go.text/encoding/simplifiedchinese"
+	"golang.org/x/text/transform"
+	"sync"
+	"unicode/utf8"
+)
+
+const jisCells = 94 * 94
+
+// cellTable is a 94x94 character set indexed by (row-1)*94+(cell-1), built
+// on first use. Unassigned points hold RuneError.
+type cellTable struct {
+	once   sync.Once
+	build  func() []rune
+	decode []rune
+	encode map[rune]int32
+}
+
+func (t *cellTable) init() {
+	t.once.Do(func() {
+		t.decode = t.build()
+		t.encode = make(map[rune]int32, len(t.decode))
+		for i, r := range t.decode {
+			if _, ok := t.encode[r]; !ok && r != RuneError {
+				t.encode[r] = int32(i)
+			}
+		}
+	})
+}
+
+// rune returns the character at index i.
+func (t *cellTable) rune(i int) rune {
+	t.init()
+	if i < 0 || len(t.decode) <= i {
+		return RuneError
+	}
+	return t.decode[i]
+}
+
+// index returns the index of r, or -1.
+func (t *cellTable) index(r rune) int {
+	t.init()
+	if i, ok := t.encode[r]; ok {
+		return int(i)
+	}
+	return -1
+}
+
+// buildCellTable decodes every point of a 94x94 set from its EUC form
+// behind prefix.
+func buildCellTable(d transform.Transformer, prefix []byte) []rune {
+	t := make([]rune, jisCells)
+	for i := range t {
+		b := append(append([]byte{}, prefix...), byte(0xa1+i/94), byte(0xa1+i%94))
+		d.Reset()
+		dst, _, err := transform.Bytes(d, b)
+		r, size := utf8.DecodeRune(dst)
+		if err != nil || size != len(dst) {
+			r = RuneError
+		}
+		t[i] = r
+	}
+	return t
+}
+
+// JIS X 0208 and JIS X 0212 are built from the EUC-JP decoder, GB 2312 and
+// KS X 1001 from the GBK and EUC-KR decoders.
+var (
+	jis0208Cells = &cellTable{build: func() []rune {
+		return buildCellTable(japanese.EUCJP.NewDecoder(), nil)
+	}}
+	jis0212Cells = &cellTable{build: func() []rune {
+		return buildCellTable(japanese.EUCJP.NewDecoder(), []byte{0x8f})
+	}}
+	gb2312Cells = &cellTable{build: func() []rune {
+		t := buildCellTable(simplifiedchinese.GBK.NewDecoder(), nil)
+		// GBK puts more characters and user-defined ones where GB 2312
+		// has none.
+		for i := range t {
+			if !gb2312Assigned(i) {
+				t[i] = RuneError
+			}
+		}
+		return t
+	}}
+	ksc5601Cells = &cellTable{build: func() []rune {
+		return buildCellTable(korean.EUCKR.NewDecoder(), nil)
+	}}
+)
+
+// gb2312Ranges holds the assigned cells of GB 2312 as ranges of their
+// EUC-CN codes.
+var gb2312Ranges = [][2]uint16{
+	{0xa1a1, 0xa1fe},
+	{0xa2b1, 0xa2e2}, {0xa2e5, 0xa2ee}, {0xa2f1, 0xa2fc},
+	{0xa3a1, 0xa3fe},
+	{0xa4a1, 0xa4f3},
+	{0xa5a1, 0xa5f6},
+	{0xa6a1, 0xa6b8}, {0xa6c1, 0xa6d8},
+	{0xa7a1, 0xa7c1}, {0xa7d1, 0xa7f1},
+	{0xa8a1, 0xa8ba}, {0xa8c5, 0xa8e9},
+	{0xa9a4, 0xa9ef},
+	{0xb0a1, 0xd7f9}, // level 1 hanzi
+	{0xd8a1, 0xf7fe}, // level 2 hanzi
+}
+
+// gb2312Assigned reports whether GB 2312 has a character at index i.
+func gb2312Assigned(i int) bool {
+	c := uint16(0xa1+i/94)<<8 | uint16(0xa1+i%94)
+	for _, r := range gb2312Ranges {
+		if r[0] <= c && c <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// jis0208Rune returns the character at index i of JIS X 0208.
+func jis0208Rune(i int) rune {
+	return jis0208Cells.rune(i)
+}
+
+// jis0212Rune returns the character at index i of JIS X 0212.
+func jis0212Rune(i int) rune {
+	return jis0212Cells.rune(i)
+}
+
+// JIS X 0213 is built from jis0213Rows, with both planes in one table: the
+// second plane starts at jisCells.
+var (
+	jis0213Once    sync.Once
+	jis0213Decode  []rune
+	jis0213Encode  map[rune]int32
+	jis0213Compose map[[2]rune]int32
+)
+
+func buildJIS0213() {
+	jis0213Decode = make([]rune, 2*jisCells)
+	jis0213Encode = make(map[rune]int32)
+	jis0213Compose = make(map[[2]rune]int32, len(jis0213Pairs))
+	for i := range jis0213Decode {
+		jis0213Decode[i] = RuneError
+	}
+	for row, s := range jis0213Rows {
+		i := row * 94
+		for _, r := range s {
+			if r != 0 {
+				jis0213Decode[i] = r
+				if _, ok := jis0213Encode[r]; !ok {
+					jis0213Encode[r] = int32(i)
+				}
+			}
+			i++
+		}
+	}
+	for i, p := range jis0213Pairs {
+		jis0213Compose[p] = int32(i)
+	}
+}
+
+// jis0213Rune returns the character at index i of JIS X 0213 and, for the
+// cells that hold a sequence, the combining character that follows it.
+func jis0213Rune(i int) (r, comb rune) {
+	jis0213Once.Do(buildJIS0213)
+	if p, ok := jis0213Pairs[i]; ok {
+		return p[0], p[1]
+	}
+	if i < 0 || len(jis0213Decode) <= i {
+		return RuneError, 0
+	}
+	return jis0213Decode[i], 0
+}
+
+// jis0213Index returns the index of r, or of r followed by next where they
+// form a sequence of one cell, and the number of characters it consumed.
+// It returns -1 if JIS X 0213 lacks r.
+func jis0213Index(r, next rune) (i, n int) {
+	jis0213Once.Do(buildJIS0213)
+	if i, ok := jis0213Compose[[2]rune{r, next}]; ok {
+		return int(i), 2
+	}
+	if i, ok := jis0213Encode[r]; ok {
+		return int(i), 1
+	}
+	return -1, 0
+}
@@ -0,0 +1,25 @@
+package encoding
+
+import (
+	"testing"
+)
+
+// JIS X 0213 leaves the rows of plane 2 that JIS X 0212 uses unassigned.
+var jis0213Plane2Rows = map[int]bool{
+	1: true, 3: true, 4: true, 5: true, 8: true, 12: true, 13: true, 14: true, 15: true,
+}
+
+func TestJIS0213Rows(t *testing.T) {
+	for i, s := range jis0213Rows {
+		plane, row := i/94+1, i%94+1
+		assigned := plane == 1 || jis0213Plane2Rows[row] || row >= 78
+		if n := len([]rune(s)); assigned && n != 94 || !assigned && n != 0 {
+			t.Errorf("plane %d, row %d has %d cells", plane, row, n)
+		}
+	}
+	for i, p := range jis0213Pairs {
+		if k, n := jis0213Index(p[0], p[1]); k != i || n != 2 {
+			t.Errorf("%U %U encodes as cell %d, %d characters; want cell %d", p[0], p[1], k, n, i)
+		}
+	}
+}
@@ -0,0 +1,249 @@
+package encoding
+
+import (
+	"golang.org/x/text/transform"
+	"strings"
+	"unicode/utf8"
+)
+
+// ShiftJIS2004 is Shift_JIS-2004, Shift JIS for JIS X 0213:2004. Its first
+// plane extends JIS X 0208; the second plane takes the lead bytes
+// 0xF0-0xFC, which CP932 reads as user-defined characters. Detection prefers
+// it to CP932 when the characters only JIS X 0213 has score better.
+var ShiftJIS2004 *jis2004 = &jis2004{
+	splitter: &splitter{},
+}
+
+// EUCJIS2004 is EUC-JIS-2004, EUC-JP for JIS X 0213:2004 with the second
+// plane behind 0x8F.
+var EUCJIS2004 *jis2004 = &jis2004{
+	splitter: &splitter{},
+	euc:      true,
+}
+
+type jis2004 struct {
+	*splitter
+	euc bool
+}
+
+func (e jis2004) String() string {
+	if e.euc {
+		return "EUC-JIS-2004"
+	}
+	return "Shift_JIS-2004"
+}
+
+func (e *jis2004) NewEncodingSearcher() EncodingSearcher {
+	d := newJIS2004Decoder(e.euc)
+	d.detect = true
+	return d
+}
+
+func (e *jis2004) newValidator() EncodingSearcher {
+	return newJIS2004Decoder(e.euc)
+}
+
+// sjis2004Plane2 lists the rows of the second plane by lead byte from 0xF0,
+// two rows per lead byte as in the first plane.
+var sjis2004Plane2 = [...][2]int{
+	{1, 8}, {3, 4}, {5, 12}, {13, 14}, {15, 78},
+	{79, 80}, {81, 82}, {83, 84}, {85, 86}, {87, 88}, {89, 90}, {91, 92}, {93, 94},
+}
+
+// sjis2004Index returns the JIS X 0213 index of a double-byte code, or -1.
+func sjis2004Index(c0, c1 byte) int {
+	var t int
+	switch {
+	case 0x40 <= c1 && c1 < 0x7f:
+		t = int(c1) - 0x40
+	case 0x80 <= c1 && c1 < 0xfd:
+		t = int(c1) - 0x41
+	default:
+		return -1
+	}
+	half, cell := t/94, t%94
+	var row int
+	switch {
+	case 0x81 <= c0 && c0 < 0xa0:
+		row = 2*int(c0-0x81) + half
+	case 0xe0 <= c0 && c0 < 0xf0:
+		row = 2*int(c0-0xe0+0x1f) + half
+	case 0xf0 <= c0 && c0 < 0xfd:
+		row = jisCells/94 + sjis2004Plane2[c0-0xf0][half] - 1
+	default:
+		return -1
+	}
+	return row*94 + cell
+}
+
+// sjis2004Bytes returns the code of a JIS X 0213 index.
+func sjis2004Bytes(i int) (c0, c1 byte) {
+	row, cell := i/94, i%94
+	half := row % 2
+	switch {
+	case row < 62:
+		c0 = byte(0x81 + row/2)
+	case row < 94:
+		c0 = byte(0xe0 + row/2 - 0x1f)
+	default:
+		for k, rows := range sjis2004Plane2 {
+			if rows[0] == row-93 || rows[1] == row-93 {
+				c0 = byte(0xf0 + k)
+				half = 0
+				if rows[1] == row-93 {
+					half = 1
+				}
+			}
+		}
+	}
+	t := half*94 + cell
+	if t < 0x3f {
+		c1 = byte(0x40 + t)
+	} else {
+		c1 = byte(0x41 + t)
+	}
+	return c0, c1
+}
+
+// jis2004Decoder validates either form of JIS X 0213 and scores it like
+// shiftJISDecoder. With detect set, it also rejects NUL bytes, which text
+// files lack.
+type jis2004Decoder struct {
+	euc    bool
+	detect bool
+	lm     lmScorer
+}
+
+func newJIS2004Decoder(euc bool) *jis2004Decoder {
+	return &jis2004Decoder{euc: euc, lm: lmScorer{m: jaModel}}
+}
+
+// next reads the character at the start of src. It returns RuneError for
+// an unassigned code.
+func (d *jis2004Decoder) next(src []byte) (r, comb rune, size int, err error) {
+	c0 := src[0]
+	switch {
+	case c0 == 0x00 && d.detect:
+		return RuneError, 0, 0, ErrInvalidEncoding
+
+	case c0 < utf8.RuneSelf:
+		return rune(c0), 0, 1, nil
+
+	case !d.euc && 0xa1 <= c0 && c0 < 0xe0:
+		return rune(c0) + (0xff61 - 0xa1), 0, 1, nil
+
+	case d.euc && c0 == 0x8e:
+		if len(src) < 2 {
+			return RuneError, 0, 0, transform.ErrShortSrc
+		}
+		if c1 := src[1]; 0xa1 <= c1 && c1 <= 0xdf {
+			return rune(c1) + (0xff61 - 0xa1), 0, 2, nil
+		}
+
+	case d.euc:
+		i, n := 0, 2
+		if c0 == 0x8f {
+			i, n = jisCells, 3
+		}
+		if len(src) < n {
+			return RuneError, 0, 0, transform.ErrShortSrc
+		}
+		b0, b1 := src[n-2], src[n-1]
+		if 0xa1 <= b0 && b0 <= 0xfe && 0xa1 <= b1 && b1 <= 0xfe {
+			r, comb = jis0213Rune(i + int(b0-0xa1)*94 + int(b1-0xa1))
+			return r, comb, n, nil
+		}
+
+	default:
+		if len(src) < 2 {
+			return RuneError, 0, 0, transform.ErrShortSrc
+		}
+		if i := sjis2004Index(c0, src[1]); i >= 0 {
+			r, comb = jis0213Rune(i)
+			return r, comb, 2, nil
+		}
+	}
+	return RuneError, 0, 0, ErrInvalidEncoding
+}
+
+func (d *jis2004Decoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	for nSrc < len(src) {
+		r, _, size, e := d.next(src[nSrc:])
+		if e == nil && r == RuneError {
+			e = ErrInvalidEncoding
+		}
+		if e != nil {
+			err = e
+			break
+		}
+		d.lm.add(r, size)
+		nSrc += size
+	}
+	if atEOF && err == transform.ErrShortSrc {
+		err = ErrInvalidEncoding
+	}
+	return nSrc, err, d.lm.take()
+}
+
+// Decode reads unassigned codes, and bytes that start no character, as
+// RuneError, and returns ErrInvalidEncoding with the text.
+func (e *jis2004) Decode(b []byte) (string, error) {
+	d := newJIS2004Decoder(e.euc)
+	var buf strings.Builder
+	var err error
+	for len(b) > 0 {
+		r, comb, size, nerr := d.next(b)
+		if nerr != nil {
+			r, size = RuneError, 1
+		}
+		if r == RuneError {
+			err = ErrInvalidEncoding
+		}
+		buf.WriteRune(r)
+		if comb != 0 {
+			buf.WriteRune(comb)
+		}
+		b = b[size:]
+	}
+	return buf.String(), err
+}
+
+func (e *jis2004) Encode(s string) ([]byte, error) {
+	ret := make([]byte, 0, len(s))
+	rs := []rune(s)
+	for k := 0; k < len(rs); {
+		r, next := rs[k], rune(0)
+		if k+1 < len(rs) {
+			next = rs[k+1]
+		}
+		switch {
+		case r < utf8.RuneSelf:
+			ret = append(ret, byte(r))
+			k++
+			continue
+		case 0xff61 <= r && r < 0xffa0:
+			if e.euc {
+				ret = append(ret, 0x8e)
+			}
+			ret = append(ret, byte(r-(0xff61-0xa1)))
+			k++
+			continue
+		}
+		i, n := jis0213Index(r, next)
+		if i < 0 {
+			return nil, ErrUnmappable
+		}
+		switch {
+		case !e.euc:
+			c0, c1 := sjis2004Bytes(i)
+			ret = append(ret, c0, c1)
+		case i < jisCells:
+			ret = append(ret, byte(0xa1+i/94), byte(0xa1+i%94))
+		default:
+			i -= jisCells
+			ret = append(ret, 0x8f, byte(0xa1+i/94), byte(0xa1+i%94))
+		}
+		k += n
+	}
+	return ret, nil
+}
@@ -0,0 +1,69 @@
+package encoding
+
+import (
+	"testing"
+)
+
+func TestSJIS2004Codes(t *testing.T) {
+	for i, s := range jis0213Rows {
+		if s == "" {
+			continue
+		}
+		for cell := 0; cell < 94; cell++ {
+			c0, c1 := sjis2004Bytes(i*94 + cell)
+			if k := sjis2004Index(c0, c1); k != i*94+cell {
+				t.Fatalf("index %d encodes as % x, which reads as %d", i*94+cell, []byte{c0, c1}, k)
+			}
+		}
+	}
+}
+
+func TestJIS2004(t *testing.T) {
+	tests := []struct {
+		enc *jis2004
+		s   string
+		b   string
+	}{
+		{ShiftJIS2004, "日本", "\x93\xfa\x96\x7b"},
+		{ShiftJIS2004, "俱𠀋", "\x87\x9f\x87\xa0"},
+		{ShiftJIS2004, "𠂉", "\xf0\x40"},
+		{ShiftJIS2004, "か゚", "\x82\xf5"},
+		{EUCJIS2004, "日本", "\xc6\xfc\xcb\xdc"},
+		{EUCJIS2004, "𠂉", "\x8f\xa1\xa1"},
+		{EUCJIS2004, "か゚", "\xa4\xf7"},
+	}
+	for _, tt := range tests {
+		if b := mustEncode(t, tt.enc, tt.s); string(b) != tt.b {
+			t.Errorf("%s: Encode(%q) = % x, want % x", tt.enc, tt.s, b, tt.b)
+		}
+		if s, err := tt.enc.Decode([]byte(tt.b)); s != tt.s || err != nil {
+			t.Errorf("%s: Decode(% x) = %q, %v; want %q", tt.enc, tt.b, s, err, tt.s)
+		}
+	}
+}
+
+func TestJIS2004Detection(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  Encoder
+		s    string
+		want Encoding
+	}{
+		{"plane 2", ShiftJIS2004, jaText + "𠂉田さんと丂さん\n", ShiftJIS2004},
+		{"plane 1 row 14", ShiftJIS2004, jaText + "俱楽部\n", ShiftJIS2004},
+		{"plane 1 rows 89-92", ShiftJIS2004, jaText + "磤葳\n", ShiftJIS2004},
+		{"JIS X 0208 only", ShiftJIS2004, jaText, CP932},
+		{"IBM extensions", CP932, jaText + "髙橋\n", CP932},
+		{"EUC plane 2", EUCJIS2004, jaText + "𠂉田さんと丂さん\n", EUCJIS2004},
+		{"EUC plane 1 row 14", EUCJIS2004, jaText + "俱楽部\n", EUCJIS2004},
+		{"EUC JIS X 0208 only", EUCJIS2004, jaText, EUCJP},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			det := detect(t, mustEncode(t, tt.enc, tt.s))
+			if det.Encoding != tt.want {
+				t.Errorf("Encoding = %s, want %s", name(det.Encoding), name(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package encoding
+
+import (
+	"math"
+	"unicode/utf8"
+)
+
+// Character classes of the language models.
+const (
+	classASCII = iota
+	classHiragana
+	classKatakana
+	classHan
+	classHangul
+	classPunct
+	classHalfwidth
+	classOther
+	classInvalid
+	numClasses
+)
+
+// randomBits is the cost of a byte of random data. A character scores the
+// bits it saves against random data of the same length.
+const randomBits = 7.0
+
+// invalidCost is the cost of an unassigned character.
+const invalidCost = 24.0
+
+func classOf(r rune) int {
+	switch {
+	case r < utf8.RuneSelf:
+		return classASCII
+	case r == RuneError || 0xe000 <= r && r < 0xf900:
+		return classInvalid
+	case 0x3041 <= r && r < 0x30a0:
+		return classHiragana
+	case 0x30a0 <= r && r < 0x3100:
+		return classKatakana
+	case 0x3400 <= r && r < 0xa000, 0xf900 <= r && r < 0xfb00, 0x20000 <= r && r < 0x30000:
+		return classHan
+	case 0xac00 <= r && r < 0xd7a4, 0x3130 <= r && r < 0x3190:
+		return classHangul
+	case 0xff61 <= r && r < 0xffa0:
+		return classHalfwidth
+	case 0x3000 <= r && r < 0x3040, 0xff00 <= r && r < 0xff61, 0xffe0 <= r && r < 0xfff0,
+		0x2010 <= r && r < 0x2070, 0x2190 <= r && r < 0x2700, 0x00a0 <= r && r < 0x0100:
+		return classPunct
+	}
+	return classOther
+}
+
+// classSizes is the number of characters assumed in each class for
+// characters outside the frequency tables.
+var classSizes = [numClasses]int{
+	classASCII:     95,
+	classHiragana:  86,
+	classKatakana:  90,
+	classHan:       6000,
+	classHangul:    2350,
+	classPunct:     300,
+	classHalfwidth: 63,
+	classOther:     500,
+	classInvalid:   1,
+}
+
+// langSpec describes a language model in probabilities.
+type langSpec struct {
+	name string
+
+	// trans[p][c] is the probability of class c following class p.
+	trans [numClasses][numClasses]float64
+
+	// freq lists characters of a class by falling frequency; together
+	// they cover share of the class.
+	freq  map[int]string
+	share map[int]float64
+
+	// bigrams are common character pairs, each worth bonus bits.
+	bigrams []string
+	bonus   float64
+}
+
+// langModel is a class bigram model of a language with costs in bits.
+type langModel struct {
+	name      string
+	trans     [numClasses][numClasses]float64
+	runeCost  map[rune]float64
+	classCost [numClasses]float64
+	bigrams   map[[2]rune]float64
+}
+
+func newLangModel(s *langSpec) *langModel {
+	m := &langModel{
+		name:     s.name,
+		runeCost: map[rune]float64{},
+		bigrams:  map[[2]rune]float64{},
+	}
+	for p := range s.trans {
+		for c, pr := range s.trans[p] {
+			m.trans[p][c] = cost(pr)
+		}
+	}
+	for c := range m.classCost {
+		m.classCost[c] = math.Log2(float64(classSizes[c]))
+	}
+	for c, chars := range s.freq {
+		rs := []rune(chars)
+		// Zipf's law: the n-th character has a frequency of 1/n.
+		h := 0.0
+		for n := range rs {
+			h += 1 / float64(n+1)
+		}
+		for n, r := range rs {
+			if _, ok := m.runeCost[r]; !ok {
+				m.runeCost[r] = cost(s.share[c] / float64(n+1) / h)
+			}
+		}
+		if rest := classSizes[c] - len(rs); rest > 0 {
+			m.classCost[c] = cost((1 - s.share[c]) / float64(rest))
+		}
+	}
+	for _, b := range s.bigrams {
+		rs := []rune(b)
+		m.bigrams[[2]rune{rs[0], rs[1]}] = s.bonus
+	}
+	m.classCost[classInvalid] = invalidCost
+	return m
+}
+
+func cost(p float64) float64 {
+	if p <= 0 {
+		return invalidCost
+	}
+	return -math.Log2(p)
+}
+
+// bits returns the bits saved by r against n random bytes, given the
+// previous character prev.
+func (m *langModel) bits(prev, r rune, n int) float64 {
+	c := classOf(r)
+	bits := m.trans[classOf(prev)][c]
+	if rc, ok := m.runeCost[r]; ok {
+		bits += rc
+	} else {
+		bits += m.classCost[c]
+	}
+	bits -= m.bigrams[[2]rune{prev, r}]
+	return randomBits*float64(n) - bits
+}
+
+// lmScorer accumulates the score of trial-decoded characters for an
+// EncodingSearcher.
+type lmScorer struct {
+	m        *langModel
+	prev     rune
+	bits     float64
+	reported int
+}
+
+// add scores r decoded from n bytes.
+func (s *lmScorer) add(r rune, n int) {
+	if r >= utf8.RuneSelf {
+		s.bits += s.m.bits(s.prev, r, n)
+	}
+	s.prev = r
+}
+
+// take returns the score gained since the last call.
+func (s *lmScorer) take() int {
+	total := int(s.bits)
+	delta := total - s.reported
+	s.reported = total
+	return delta
+}
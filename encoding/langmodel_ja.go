@@ -0,0 +1,41 @@
+package encoding
+
+// jaModel is the model of Japanese text used to tell Shift JIS and EUC-JP
+// apart.
+var jaModel = newLangModel(&langSpec{
+	name: "ja",
+	trans: [numClasses][numClasses]float64{
+		classASCII:     {classASCII: 0.12, classHiragana: 0.25, classKatakana: 0.10, classHan: 0.30, classPunct: 0.20, classHalfwidth: 0.02, classOther: 0.01, classHangul: 0.0001},
+		classHiragana:  {classASCII: 0.025, classHiragana: 0.55, classKatakana: 0.05, classHan: 0.25, classPunct: 0.12, classHalfwidth: 0.002, classOther: 0.003, classHangul: 0.0001},
+		classKatakana:  {classASCII: 0.025, classHiragana: 0.20, classKatakana: 0.60, classHan: 0.08, classPunct: 0.09, classHalfwidth: 0.002, classOther: 0.003, classHangul: 0.0001},
+		classHan:       {classASCII: 0.025, classHiragana: 0.50, classKatakana: 0.03, classHan: 0.33, classPunct: 0.11, classHalfwidth: 0.002, classOther: 0.003, classHangul: 0.0001},
+		classHangul:    {classASCII: 0.2, classHiragana: 0.2, classKatakana: 0.1, classHan: 0.2, classPunct: 0.2, classHalfwidth: 0.05, classOther: 0.05, classHangul: 0.0001},
+		classPunct:     {classASCII: 0.20, classHiragana: 0.25, classKatakana: 0.08, classHan: 0.30, classPunct: 0.15, classHalfwidth: 0.01, classOther: 0.01, classHangul: 0.0001},
+		classHalfwidth: {classASCII: 0.08, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.01, classPunct: 0.03, classHalfwidth: 0.85, classOther: 0.01, classHangul: 0.0001},
+		classOther:     {classASCII: 0.2, classHiragana: 0.2, classKatakana: 0.1, classHan: 0.2, classPunct: 0.2, classHalfwidth: 0.05, classOther: 0.05, classHangul: 0.0001},
+		classInvalid:   {classASCII: 0.2, classHiragana: 0.2, classKatakana: 0.1, classHan: 0.2, classPunct: 0.2, classHalfwidth: 0.05, classOther: 0.05, classHangul: 0.0001},
+	},
+	freq: map[int]string{
+		classHiragana:  "のにるたとはしいてをなかでがすこれらもまつうりくあんっさよけきだどおせえちそめわょみやじほひべぶずごげぐばびぜぞづゃゅぁぃぅぇぉぢゎ",
+		classKatakana:  "ーンルトスリイクラシタレロドフカマコアテッメキプジデミオュバャブパサグナニィポビゼソエツヴハモヤヒガゲダチヘホムヨヌネノセケゴギピペボベヂヅォァゥェヮヵヶ",
+		classHan:       "日一国人年大十二本中長出三同時政事自行社見月分議後前民生連五発間対上部東者党地合市業内相方四定今回新場金員九入選立開手米力学問高代明実円関決子動京全目表戦経通外最言氏現理調体化田当八六約主題下首意法不来作性的要用制治度務強気小七成期公持野協取都和統以機平総加山思家話世受区領多県続進正安設保改数記院女初北午指権心界支第産結百派点教報済書府活原先共得解名交資予川向際査勝面委告軍文反元重近千考判認画海参売利組知案道信策集在件団別物側任引使求所次水半品昨論計死官増係感特情投示変打男基私各始島直両朝革価式確村提運終挙果西勢減台広容必応演電歳住争談能無再位置企真流格有疑口過局少放税検藤町常校料沢裁状工建語球営空職証土与急止送援供可役構木割聞身費付施切由説転食比難防補車優夫研収断井何南石足違消境神番規術護展態導鮮備宅害配副算視条幹独警宮究育席輸訪楽起万着乗店述残想線率病農州武声質念待試族象銀域助労例衛然早張映限親額監環験追審商葉義伝働形景落欧担好退準賞訴辺造英被株頭技低毎医復仕去姿味負閣韓渡失移差衆個門写評課末守若脳極種美岡影命含福蔵量望松非撃佐核観察整段横融型白深字答夜製票況音申様財港識注呼渉達",
+		classPunct:     "、。「」・（）：！？『』〜…―＝％／＋＆＊＃＠【】〈〉《》○●□■△▲◇◆※→←↑↓〒",
+		classHalfwidth: "ｰﾞﾝｲｽﾄﾙﾘｸﾗｼﾀﾚﾛﾌｶﾏｺｱﾃｯﾒｷﾟﾐｵﾕﾊﾔﾋﾑﾖﾇﾈﾉｾｹﾁﾍﾎﾆﾅｻｿｴﾂﾓｦｧｨｩｪｫｬｭｮ｡｢｣､･",
+	},
+	share: map[int]float64{
+		classHiragana:  0.98,
+		classKatakana:  0.95,
+		classHan:       0.75,
+		classPunct:     0.90,
+		classHalfwidth: 0.95,
+	},
+	bigrams: []string{
+		"ます", "です", "した", "して", "てい", "いる", "こと", "ない", "ある", "から",
+		"この", "その", "れる", "られ", "った", "って", "ので", "もの", "よう", "ため",
+		"など", "まし", "でし", "につ", "には", "では", "との", "への", "いた", "する",
+		"され", "さん", "ませ", "ん。", "た。", "す。", "ね。", "よ。", "い。", "る。",
+		"か。", "は、", "が、", "て、", "で、", "に、", "ーム", "ート", "ール", "ショ",
+		"ｰﾄ", "ﾝﾄ", "ﾃﾞ", "ｼﾞ", "ﾀﾞ", "ﾄﾞ", "ﾊﾞ", "ﾌﾞ", "ﾎﾞ", "ｶﾞ",
+	},
+	bonus: 2,
+})
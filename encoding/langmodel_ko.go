@@ -0,0 +1,30 @@
+package encoding
+
+// koModel is the model of Korean text, used for EUC-KR.
+var koModel = newLangModel(&langSpec{
+	name: "ko",
+	trans: [numClasses][numClasses]float64{
+		classASCII:     {classASCII: 0.35, classHiragana: 0.0005, classKatakana: 0.0005, classHan: 0.01, classPunct: 0.12, classHalfwidth: 0.0001, classOther: 0.02, classHangul: 0.45},
+		classHiragana:  {classASCII: 0.3, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.05, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.3},
+		classKatakana:  {classASCII: 0.3, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.05, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.3},
+		classHan:       {classASCII: 0.10, classHiragana: 0.0005, classKatakana: 0.0005, classHan: 0.30, classPunct: 0.10, classHalfwidth: 0.0001, classOther: 0.01, classHangul: 0.50},
+		classHangul:    {classASCII: 0.12, classHiragana: 0.0002, classKatakana: 0.0002, classHan: 0.005, classPunct: 0.06, classHalfwidth: 0.0001, classOther: 0.01, classHangul: 0.80},
+		classPunct:     {classASCII: 0.30, classHiragana: 0.0005, classKatakana: 0.0005, classHan: 0.02, classPunct: 0.10, classHalfwidth: 0.0001, classOther: 0.02, classHangul: 0.50},
+		classHalfwidth: {classASCII: 0.3, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.05, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.3},
+		classOther:     {classASCII: 0.3, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.05, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.3},
+		classInvalid:   {classASCII: 0.3, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.05, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.3},
+	},
+	freq: map[int]string{
+		classHangul: "이다의는에을하고한가지를서로기사자도어리수시대들인나해게정아그있보것일적으요부만주라전과상제우위국장여성니문었면되소학계경생동비거내원화구무연신실까업터중했저마방진공관회데분식미모선같개할안물때후말발치본결조단드명금입통민행체운히반세각유와용당영오현음변건번야님",
+		classPunct:  "·“”‘’…「」『』〈〉《》",
+	},
+	share: map[int]float64{
+		classHangul: 0.75,
+		classPunct:  0.90,
+	},
+	bigrams: []string{
+		"습니", "니다", "했다", "있다", "하는", "에서", "으로", "하고", "이다", "것이",
+		"한다", "되었", "에게", "하여", "까지", "부터", "에는", "지만", "하지", "합니",
+	},
+	bonus: 2,
+})
@@ -0,0 +1,119 @@
+package encoding
+
+import (
+	"container/list"
+	"sync"
+	"testing"
+)
+
+func chunks(b []byte, size int) *list.List {
+	ls := list.New()
+	for len(b) > size {
+		ls.PushBack(b[:size])
+		b = b[size:]
+	}
+	ls.PushBack(b)
+	return ls
+}
+
+func TestLangModelJapanese(t *testing.T) {
+	// Both encodings at one priority, so that only the scores decide.
+	r := NewRegistry()
+	for _, enc := range []Encoding{EUCJP, ShiftJIS} {
+		if err := r.Register(Entry{Encoding: enc, Priority: 30}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, s := range []string{
+		"ｱｲｳｴｵｶｷｸｹｺ",
+		"こんにちは",
+		"カタカナ",
+		"東京都",
+		jaText,
+	} {
+		for _, enc := range []Encoding{ShiftJIS, EUCJP} {
+			d, err := r.NewDetector()
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.Feed(mustEncode(t, enc, s), true)
+			if det := d.Detection(); det.Encoding != enc {
+				t.Errorf("%q in %s detected as %s", s, enc, name(det.Encoding))
+			}
+		}
+	}
+}
+
+func TestCheckEncodingOf(t *testing.T) {
+	in := mustEncode(t, ShiftJIS, jaText)
+	tests := []struct {
+		enc   Encoding
+		valid bool
+	}{
+		{ShiftJIS, true},
+		{CP932, true},
+		{ShiftJIS2004, true},
+		{EUCJP, false},
+		{UTF8, false},
+	}
+	for _, tt := range tests {
+		f := tt.enc.(SearcherFactory)
+		ok1, score1 := CheckEncodingOf(chunks(in, 7), f)
+		ok2, score2 := CheckEncodingOf(chunks(in, 1<<10), f)
+		if ok1 != tt.valid || ok2 != tt.valid {
+			t.Errorf("%s: CheckEncodingOf = %v, %v; want %v", tt.enc, ok1, ok2, tt.valid)
+		}
+		// Every call starts from a fresh searcher, however the input is
+		// chunked.
+		if tt.valid && (score1 <= 0 || score1 != score2) {
+			t.Errorf("%s: scores %d and %d, want the same positive score", tt.enc, score1, score2)
+		}
+	}
+}
+
+func TestCheckEncoding(t *testing.T) {
+	// The encodings that were EncodingSearchers before SearcherFactory
+	// still are, and run as with CheckEncodingOf.
+	tests := []struct {
+		es  EncodingSearcher
+		enc Encoding
+	}{
+		{ShiftJIS, ShiftJIS},
+		{EUCJP, EUCJP},
+		{ISO2022JP, ISO2022JP},
+		{UTF16LE, UTF16LE},
+		{UTF8, UTF8},
+		{newShiftJISDecoder(), ShiftJIS}, // a searcher of its own
+	}
+	for _, tt := range tests {
+		in := mustEncode(t, tt.enc.(Encoder), jaText)
+		ok, score := CheckEncoding(chunks(in, 7), tt.es)
+		ok2, score2 := CheckEncodingOf(chunks(in, 7), tt.enc.(SearcherFactory))
+		if !ok || ok != ok2 || score != score2 {
+			t.Errorf("%s: CheckEncoding = %v, %d; want %v, %d", tt.enc, ok, score, ok2, score2)
+		}
+		if n, err, _ := tt.es.EncodingSearch(in, true); err != nil || n != len(in) {
+			t.Errorf("%s: EncodingSearch = %d, %v; want %d", tt.enc, n, err, len(in))
+		}
+	}
+}
+
+func TestDetectorsConcurrent(t *testing.T) {
+	in := mustEncode(t, EUCJP, jaText)
+	want := detect(t, in).Candidates
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d, _ := NewDetector()
+			d.Feed(in, true)
+			for k, c := range d.Detection().Candidates {
+				if c.Encoding != want[k].Encoding || c.Score != want[k].Score {
+					t.Errorf("candidate %d = %s %d, want %s %d", k, c.Encoding, c.Score, want[k].Encoding, want[k].Score)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
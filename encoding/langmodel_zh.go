@@ -0,0 +1,53 @@
+package encoding
+
+// zhTrans is the class model of Chinese text, which has little kana and
+// no Hangul.
+var zhTrans = [numClasses][numClasses]float64{
+	classASCII:     {classASCII: 0.30, classHiragana: 0.0005, classKatakana: 0.0005, classHan: 0.50, classPunct: 0.15, classHalfwidth: 0.0001, classOther: 0.02, classHangul: 0.0001},
+	classHiragana:  {classASCII: 0.2, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.5, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.0001},
+	classKatakana:  {classASCII: 0.2, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.5, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.0001},
+	classHan:       {classASCII: 0.04, classHiragana: 0.0005, classKatakana: 0.0005, classHan: 0.80, classPunct: 0.15, classHalfwidth: 0.0001, classOther: 0.005, classHangul: 0.0001},
+	classHangul:    {classASCII: 0.2, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.5, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.0001},
+	classPunct:     {classASCII: 0.15, classHiragana: 0.0005, classKatakana: 0.0005, classHan: 0.70, classPunct: 0.10, classHalfwidth: 0.0001, classOther: 0.02, classHangul: 0.0001},
+	classHalfwidth: {classASCII: 0.2, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.5, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.0001},
+	classOther:     {classASCII: 0.2, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.5, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.0001},
+	classInvalid:   {classASCII: 0.2, classHiragana: 0.01, classKatakana: 0.01, classHan: 0.5, classPunct: 0.2, classHalfwidth: 0.0001, classOther: 0.05, classHangul: 0.0001},
+}
+
+// zhHansModel is the model of simplified Chinese, used for GBK and GB18030.
+var zhHansModel = newLangModel(&langSpec{
+	name:  "zh-Hans",
+	trans: zhTrans,
+	freq: map[int]string{
+		classHan:   "的一是不了在人有我他这个们中来上大为和国地到以说时要就出会可也你对生能而子那得于着下自之年过发后作里用道行所然家种事成方多经么去法学如都同现当没动面起看定天分还进好小部其些主样理心她本前开但因只从想实日军者意无力它与长把机十民第公此已工使情明性知全三又关点正业外将两高间由问很最重并物手应战向头文体政美相见被利什二等产或新己制身果加西斯月话合回特代内信表化老给世位次度门任常先海通教儿原东声提立及比员解水名真论处走义各入几口认条平系气题活尔更别打女变四神总何电数安少报才结反受目太量再感建务做接必场件计管期市直德资命山金指",
+		classPunct: "，。、“”：；？！（）《》—…·‘’【】％",
+	},
+	share: map[int]float64{
+		classHan:   0.60,
+		classPunct: 0.95,
+	},
+	bigrams: []string{
+		"我们", "中国", "一个", "没有", "什么", "自己", "他们", "这个", "可以", "时候",
+		"已经", "因为", "所以", "但是", "发展", "工作", "问题", "经济", "社会", "政府",
+	},
+	bonus: 2,
+})
+
+// zhHantModel is the model of traditional Chinese, used for Big5.
+var zhHantModel = newLangModel(&langSpec{
+	name:  "zh-Hant",
+	trans: zhTrans,
+	freq: map[int]string{
+		classHan:   "的一是不了在人有我他這個們中來上大為和國地到以說時要就出會可也你對生能而子那得於著下自之年過發後作裡用道行所然家種事成方多經麼去法學如都同現當沒動面起看定天分還進好小部其些主樣理心她本前開但因只從想實日軍者意無力它與長把機十民第公此已工使情明性知全三又關點正業外將兩高間由問很最重並物手應戰向頭文體政美相見被利什二等產或新己制身果加西斯月話合回特代內信表化老給世位次度門任常先海通教兒原東聲提立及比員解水名真論處走義各入幾口認條平系氣題活爾更別打女變四神總何電數安少報才結反受目太量再感建務做接必場件計管期市直德資命山金指",
+		classPunct: "，。、「」：；？！（）《》—…·『』【】％",
+	},
+	share: map[int]float64{
+		classHan:   0.60,
+		classPunct: 0.95,
+	},
+	bigrams: []string{
+		"我們", "中國", "一個", "沒有", "什麼", "自己", "他們", "這個", "可以", "時候",
+		"已經", "因為", "所以", "但是", "發展", "工作", "問題", "經濟", "社會", "政府",
+	},
+	bonus: 2,
+})
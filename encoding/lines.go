@@ -0,0 +1,207 @@
+package encoding
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"fmt"
+)
+
+// ErrLowConfidence is returned by Repair for a run whose encoding is not
+// certain enough to transcode it.
+var ErrLowConfidence = errors.New("encoding detected with low confidence")
+
+// repairConfidence is the Confidence a run needs for Repair.
+const repairConfidence = 0.8
+
+// AnalyzeOptions configures AnalyzeLines. A nil *AnalyzeOptions uses the
+// defaults.
+type AnalyzeOptions struct {
+	// Splitter splits the input into lines. Nil means lines that end in
+	// LF, as in every ASCII-compatible encoding.
+	Splitter Splitter
+
+	// Registry holds the encodings to choose from. Nil means
+	// DefaultRegistry.
+	Registry *Registry
+
+	// Candidates, if not empty, restricts detection to these encodings.
+	Candidates []Encoding
+}
+
+// Run is a run of consecutive lines in one encoding.
+type Run struct {
+	// Encoding is the encoding of the lines, or nil if no candidate was
+	// valid for them.
+	Encoding Encoding
+
+	// Start and End are the numbers of the first and the last line of the
+	// run, counted from 1.
+	Start, End int
+
+	// Lines holds the lines with their line endings.
+	Lines [][]byte
+
+	// Confidence is the share of the evidence over all lines of the run
+	// that supports its decoding, among the candidates that could take the
+	// run from Encoding: those of its priority band or a smaller one that
+	// do not decode the lines to unlikely text. Candidates that decode the
+	// lines to the same text as Encoding count with it.
+	Confidence float64
+
+	// Ambiguous is set when one of those candidates decodes the lines to
+	// other text and ranks before Encoding or has at least its evidence.
+	Ambiguous bool
+}
+
+// LineAnalysis is the result of AnalyzeLines.
+type LineAnalysis struct {
+	Runs []Run
+}
+
+// Mixed reports whether the lines are in more than one encoding, or some
+// are in none.
+func (a *LineAnalysis) Mixed() bool {
+	return len(a.Runs) > 1 || len(a.Runs) == 1 && a.Runs[0].Encoding == nil
+}
+
+// AnalyzeLines detects the encoding of every line of the chunks in ls on
+// its own, for files that were put together from differently encoded
+// parts. A line continues the run before it as long as it is valid in the
+// run's encoding and does not decode to unlikely text in it; otherwise it
+// starts a run in the encoding detected for it. Every run is then rated by
+// a detection over all its lines. It fails if a candidate is not
+// registered.
+func AnalyzeLines(ls *list.List, opts *AnalyzeOptions) (*LineAnalysis, error) {
+	if opts == nil {
+		opts = &AnalyzeOptions{}
+	}
+	sp := opts.Splitter
+	if sp == nil {
+		sp = &splitter{}
+	}
+	reg := opts.Registry
+	if reg == nil {
+		reg = DefaultRegistry
+	}
+	if _, err := reg.NewDetector(opts.Candidates...); err != nil {
+		return nil, err
+	}
+
+	lines := list.New()
+	for e := ls.Front(); e != nil; e = e.Next() {
+		sp.Split(e.Value.([]byte), e.Next() == nil, lines)
+	}
+
+	a := &LineAnalysis{}
+	n := 0
+	for e := lines.Front(); e != nil; e = e.Next() {
+		b := e.Value.([]byte)
+		n++
+
+		d, _ := reg.NewDetector(opts.Candidates...)
+		d.Feed(b, true)
+		det := d.Detection()
+
+		if k := len(a.Runs) - 1; k >= 0 {
+			run := &a.Runs[k]
+			if continues(run, det) {
+				run.End = n
+				run.Lines = append(run.Lines, b)
+				continue
+			}
+			if run.Encoding != nil && isASCII(run.Encoding) && det.Encoding != nil && det.BOM == nil {
+				// The ASCII lines so far belong to the encoding that
+				// shows first.
+				run.Encoding = det.Encoding
+				run.End = n
+				run.Lines = append(run.Lines, b)
+				continue
+			}
+		}
+		a.Runs = append(a.Runs, Run{Encoding: det.Encoding, Start: n, End: n, Lines: [][]byte{b}})
+	}
+	for i := range a.Runs {
+		a.Runs[i].rate(reg, opts.Candidates)
+	}
+	return a, nil
+}
+
+func continues(run *Run, det *Detection) bool {
+	if run.Encoding == nil || det.Encoding == nil {
+		return run.Encoding == det.Encoding
+	}
+	c, ok := det.Lookup(run.Encoding)
+	return ok && c.Valid && !c.implausible()
+}
+
+// rate sets the Confidence and Ambiguous of run.
+func (run *Run) rate(reg *Registry, only []Encoding) {
+	if run.Encoding == nil {
+		return
+	}
+	d, _ := reg.NewDetector(only...)
+	for i, b := range run.Lines {
+		d.Feed(b, i == len(run.Lines)-1)
+	}
+	det := d.Detection()
+	sel, _ := det.Lookup(run.Encoding)
+	if !sel.Valid {
+		return
+	}
+
+	all := bytes.Join(run.Lines, nil)
+	want, _ := run.Encoding.Decode(TrimBOM(all, run.Encoding))
+	agree, total := 0.0, 0.0
+	for _, c := range det.Candidates {
+		if !c.Valid || c.implausible() || c.band > sel.band {
+			continue
+		}
+		total += c.evidence()
+		if s, err := c.Encoding.Decode(TrimBOM(all, c.Encoding)); err == nil && s == want {
+			agree += c.evidence()
+		} else if c.band < sel.band || c.evidence() >= sel.evidence() {
+			run.Ambiguous = true
+		}
+	}
+	if total > 0 {
+		run.Confidence = agree / total
+	}
+}
+
+func isASCII(enc Encoding) bool {
+	return enc.String() == ASCII.String()
+}
+
+// Repair decodes the lines of every run and encodes them in enc. It fails
+// on lines that are in no encoding or that enc cannot represent, and with
+// ErrLowConfidence on runs that are ambiguous or whose Confidence is below
+// 0.8.
+func (a *LineAnalysis) Repair(enc Encoding) (*list.List, error) {
+	ls := list.New()
+	for _, run := range a.Runs {
+		if run.Encoding == nil {
+			return nil, fmt.Errorf("line %d: %w", run.Start, ErrInvalidEncoding)
+		}
+		if run.Ambiguous || run.Confidence < repairConfidence {
+			return nil, fmt.Errorf("lines %d-%d: %s: %w", run.Start, run.End, run.Encoding, ErrLowConfidence)
+		}
+		for i, b := range run.Lines {
+			s, err := run.Encoding.Decode(TrimBOM(b, run.Encoding))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", run.Start+i, err)
+			}
+			out, err := enc.Encode(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", run.Start+i, err)
+			}
+			if ls.Len() > 0 {
+				// Encoders that expect a byte order mark put one in front
+				// of every call.
+				out = trimEncodedBOM(out, enc)
+			}
+			ls.PushBack(out)
+		}
+	}
+	return ls, nil
+}
@@ -0,0 +1,104 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+type runWant struct {
+	enc        Encoding
+	start, end int
+}
+
+func TestAnalyzeLines(t *testing.T) {
+	sjis := func(s string) []byte { return mustEncode(t, ShiftJIS, s) }
+	cat := func(bs ...[]byte) []byte { return bytes.Join(bs, nil) }
+	tests := []struct {
+		name   string
+		in     []byte
+		runs   []runWant
+		repair string
+		err    error
+	}{
+		{
+			"one encoding",
+			cat([]byte("abc\n"), sjis(jaText)),
+			[]runWant{{CP932, 1, 3}},
+			"abc\n" + jaText, nil,
+		},
+		{
+			// A line that is valid, but scores nothing in the run's
+			// encoding stays in the run.
+			"mixed",
+			cat([]byte("hello, world\n"), sjis("今日は良い天気ですね。\n"), []byte("second ascii line\n"),
+				[]byte("こんにちは、世界\n"), sjis("山田太郎\n")),
+			[]runWant{{CP932, 1, 3}, {UTF8, 4, 4}, {CP932, 5, 5}},
+			"hello, world\n今日は良い天気ですね。\nsecond ascii line\nこんにちは、世界\n山田太郎\n", nil,
+		},
+		{
+			"invalid line",
+			[]byte("abc\n\x00\x80\n"),
+			[]runWant{{ASCII, 1, 1}, {nil, 2, 2}},
+			"", ErrInvalidEncoding,
+		},
+		{
+			"low confidence",
+			cat([]byte("abc\n"), mustEncode(t, EUCKR, "김철수\n")),
+			[]runWant{{EUCKR, 1, 2}},
+			"", ErrLowConfidence,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := AnalyzeLines(chunks(tt.in, 5), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(a.Runs) != len(tt.runs) {
+				t.Fatalf("got %d runs, want %d", len(a.Runs), len(tt.runs))
+			}
+			for i, r := range a.Runs {
+				w := tt.runs[i]
+				if name(r.Encoding) != name(w.enc) || r.Start != w.start || r.End != w.end {
+					t.Errorf("run %d = %s %d-%d, want %s %d-%d", i, name(r.Encoding), r.Start, r.End, name(w.enc), w.start, w.end)
+				}
+			}
+			if a.Mixed() != (len(tt.runs) > 1 || tt.runs[0].enc == nil) {
+				t.Errorf("Mixed() = %v", a.Mixed())
+			}
+
+			ls, err := a.Repair(UTF8)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Repair: %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var out []byte
+			for e := ls.Front(); e != nil; e = e.Next() {
+				out = append(out, e.Value.([]byte)...)
+			}
+			if string(out) != tt.repair {
+				t.Errorf("Repair = %q, want %q", out, tt.repair)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLinesConfidence(t *testing.T) {
+	a, err := AnalyzeLines(chunks(mustEncode(t, EUCJP, jaText), 8), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := a.Runs[0]
+	if r.Encoding != EUCJP || r.Ambiguous || r.Confidence < 0.8 {
+		t.Errorf("run = %s, confidence %v, ambiguous %v", name(r.Encoding), r.Confidence, r.Ambiguous)
+	}
+	if _, err := AnalyzeLines(chunks([]byte("abc\n"), 8), &AnalyzeOptions{Candidates: []Encoding{UTF16}}); err == nil {
+		t.Errorf("AnalyzeLines with an unregistered candidate did not fail")
+	}
+}
@@ -0,0 +1,218 @@
+package encoding
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Policy is what a conversion does with a character it cannot convert:
+// invalid input when decoding, an unmappable character when encoding.
+type Policy int
+
+const (
+	// Strict fails with an *Error.
+	Strict Policy = iota
+
+	// Replace puts Options.Replacement in place of the character.
+	Replace
+
+	// Skip drops the character.
+	Skip
+
+	// Escape writes the character in the form of Options.Escape.
+	Escape
+)
+
+// EscapeStyle is the form Escape writes characters in. When decoding, every
+// invalid byte is escaped as if it were the character of its value.
+type EscapeStyle int
+
+const (
+	// EscapeNCR writes decimal character references, as in "&#8364;".
+	EscapeNCR EscapeStyle = iota
+
+	// EscapeUnicode writes "\u20AC", with a surrogate pair for characters
+	// beyond the Basic Multilingual Plane.
+	EscapeUnicode
+
+	// EscapeHex writes the bytes of the character, in UTF-8 when
+	// encoding, as in "<E2><82><AC>".
+	EscapeHex
+)
+
+// Options configures DecodeWith and EncodeWith. The zero value is Strict.
+type Options struct {
+	Policy Policy
+
+	// Replacement is what Replace puts in place of a character. Empty
+	// means U+FFFD when decoding and "?" when encoding; "〓", the
+	// geta mark, is common in Japanese text.
+	Replacement string
+
+	Escape EscapeStyle
+
+	// Decoder, if not nil, decodes in place of the Decode of the encoding,
+	// such as a LineDecoder.
+	Decoder Decoder
+
+	// Fallback, if not nil, is consulted for a character before the
+	// policy when encoding.
+	Fallback *Fallback
+
+	// Variants, when encoding, writes the characters that the JIS and the
+	// Microsoft tables map differently in this convention first, counting
+	// the replaced ones in VariantReport if it is not nil.
+	Variants      VariantConvention
+	VariantReport VariantReport
+}
+
+func (o *Options) escape(s string, b []byte) string {
+	var buf strings.Builder
+	switch o.Escape {
+	case EscapeNCR:
+		for _, r := range s {
+			fmt.Fprintf(&buf, "&#%d;", r)
+		}
+	case EscapeUnicode:
+		for _, r := range s {
+			if r1, r2 := utf16.EncodeRune(r); r1 != RuneError {
+				fmt.Fprintf(&buf, "\\u%04X\\u%04X", r1, r2)
+			} else {
+				fmt.Fprintf(&buf, "\\u%04X", r)
+			}
+		}
+	default:
+		for _, c := range b {
+			fmt.Fprintf(&buf, "<%02X>", c)
+		}
+	}
+	return buf.String()
+}
+
+// DecodeWith decodes b, a single line, in enc and handles invalid input by
+// the policy of opts. Invalid input is found by the EncodingSearcher of enc;
+// in encodings without one, Strict fails with the error of Decode and the
+// other policies keep the RuneError that Decode reads invalid input as.
+func DecodeWith(enc Encoding, b []byte, opts Options) (string, error) {
+	var dec Decoder = enc
+	if opts.Decoder != nil {
+		dec = opts.Decoder
+	}
+
+	// Take the invalid characters out one at a time, validating what is
+	// left as a whole so that the shift state of a stateful encoding
+	// carries over them, and decode the rest in one go. The substitutes go
+	// at the columns of the characters they stand for.
+	type sub struct {
+		column int
+		s      string
+	}
+	var subs []sub
+	for {
+		err := Validate(enc, b)
+		if err != nil && opts.Policy == Strict {
+			return "", err
+		}
+		e, ok := err.(*Error)
+		if !ok || len(e.Bytes) == 0 {
+			break
+		}
+		subs = append(subs, sub{e.Column, opts.substitute(e.Bytes)})
+		n := int(e.Offset)
+		b = append(b[:n:n], b[n+len(e.Bytes):]...)
+	}
+	s, err := dec.Decode(b)
+	if err != nil && opts.Policy == Strict {
+		return "", err
+	}
+	if len(subs) == 0 {
+		return s, nil
+	}
+
+	var buf strings.Builder
+	column := 1
+	for _, r := range s {
+		for len(subs) > 0 && subs[0].column == column {
+			buf.WriteString(subs[0].s)
+			subs = subs[1:]
+		}
+		buf.WriteRune(r)
+		column++
+	}
+	for _, sb := range subs {
+		buf.WriteString(sb.s)
+	}
+	return buf.String(), nil
+}
+
+// substitute returns what the policy puts in place of the invalid bytes b.
+func (o *Options) substitute(b []byte) string {
+	switch o.Policy {
+	case Replace:
+		if o.Replacement == "" {
+			return string(RuneError)
+		}
+		return o.Replacement
+	case Escape:
+		rs := make([]rune, len(b))
+		for i, c := range b {
+			rs[i] = rune(c)
+		}
+		return o.escape(string(rs), b)
+	}
+	return ""
+}
+
+// EncodeWith encodes s in enc and handles the characters enc cannot encode
+// by the fallback and the policy of opts. Replacements and escapes must be
+// encodable in enc themselves.
+func EncodeWith(enc Encoder, s string, opts Options) ([]byte, error) {
+	s = encodeVariants(enc, s, opts.Variants, opts.VariantReport)
+	b, err := enc.Encode(s)
+	if err == nil {
+		return b, nil
+	}
+	if opts.Policy == Strict && opts.Fallback == nil {
+		return nil, NewEncodeError(enc, s, err)
+	}
+
+	// Rewrite the characters that do not encode on their own, and encode
+	// the result in one go to keep the state of stateful encoders.
+	var buf strings.Builder
+	for i := 0; i < len(s); {
+		if n := encodableLen(enc, s[i:]); n > 0 {
+			buf.WriteString(s[i : i+n])
+			i += n
+			continue
+		}
+		r, n := utf8.DecodeRuneInString(s[i:])
+		if fs, ok := opts.Fallback.lookup(enc, r); ok {
+			buf.WriteString(fs)
+			i += n
+			continue
+		}
+		switch opts.Policy {
+		case Strict:
+			e := NewEncodeError(enc, buf.String()+string(r), err)
+			e.Column = utf8.RuneCountInString(s[:i]) + 1
+			return nil, e
+		case Replace:
+			rp := opts.Replacement
+			if rp == "" {
+				rp = "?"
+			}
+			buf.WriteString(rp)
+		case Escape:
+			buf.WriteString(opts.escape(string(r), []byte(string(r))))
+		}
+		i += n
+	}
+	t := buf.String()
+	b, err = enc.Encode(t)
+	if err != nil {
+		return nil, NewEncodeError(enc, t, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,154 @@
+package encoding
+
+import (
+	"errors"
+	"testing"
+)
+
+// noSearcher hides the EncodingSearcher of an encoding.
+type noSearcher struct {
+	Encoding
+}
+
+func TestDecodeWith(t *testing.T) {
+	tests := []struct {
+		name    string
+		enc     Encoding
+		in      string
+		replace string // with Replace and "〓"
+		skip    string
+	}{
+		{"ASCII", ASCII, "a\x80b", "a〓b", "ab"},
+		{"CP932", CP932, "a\x81 b", "a〓 b", "a b"},
+		{"eucJP-ms", EUCJPMS, "a\xa1b", "a〓b", "ab"},
+		{"EUC-JIS-2004", EUCJIS2004, "a\xa1b", "a〓b", "ab"},
+		{"Shift_JIS-2004", ShiftJIS2004, "a\x81 b", "a〓 b", "a b"},
+		{"ISO-2022-JP-2", ISO2022JP2, "a\x80b", "a〓b", "ab"},
+		{"UTF-32", UTF32LE, "a\x00\x00\x00\x00\xd8\x00\x00", "a〓", "a"},
+		{"Windows-1252", Windows1252, "a\x81b", "a〓b", "ab"},
+		// Without an EncodingSearcher, the RuneError of Decode stays.
+		{"no searcher", noSearcher{CP932}, "a\x81 b", "a� b", "a� b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.enc.Decode([]byte(tt.in)); !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("Decode: %v, want %v", err, ErrInvalidEncoding)
+			}
+			if s, err := DecodeWith(tt.enc, []byte(tt.in), Options{}); !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("Strict: %q, %v; want %v", s, err, ErrInvalidEncoding)
+			}
+			s, err := DecodeWith(tt.enc, []byte(tt.in), Options{Policy: Replace, Replacement: "〓"})
+			if err != nil || s != tt.replace {
+				t.Errorf("Replace: %q, %v; want %q", s, err, tt.replace)
+			}
+			s, err = DecodeWith(tt.enc, []byte(tt.in), Options{Policy: Skip})
+			if err != nil || s != tt.skip {
+				t.Errorf("Skip: %q, %v; want %q", s, err, tt.skip)
+			}
+		})
+	}
+}
+
+func TestDecodeWithValid(t *testing.T) {
+	tests := []struct {
+		enc  Encoding
+		in   string
+		want string
+	}{
+		{ASCII, "a\x00b\n", "a\x00b\n"},
+		{CP932, "\x87\x40\n", "①\n"},
+		{EUCJPMS, "\xa4\xa2\n", "あ\n"},
+		{EUCJIS2004, "\x00\xa4\xa2\n", "\x00あ\n"},
+		{ISO2022JP2, "\x1b$BF|\x1b(B\x00\n", "日\x00\n"},
+		{UTF32LE, "a\x00\x00\x00", "a"},
+		{Windows1252, "\x80\n", "€\n"},
+		{noSearcher{CP932}, "\x87\x40\n", "①\n"},
+	}
+	for _, tt := range tests {
+		s, err := DecodeWith(tt.enc, []byte(tt.in), Options{})
+		if err != nil || s != tt.want {
+			t.Errorf("%s: DecodeWith(%q) = %q, %v; want %q", tt.enc, tt.in, s, err, tt.want)
+		}
+	}
+}
+
+func TestDecodeWithShiftState(t *testing.T) {
+	// The shift state carries over the invalid bytes.
+	tests := []struct {
+		name string
+		enc  Encoding
+		in   string
+		opts Options
+		want string
+	}{
+		{"ISO-2022-JP", ISO2022JP, "\x1b$B$3$s\xff$K$A$O\x1b(B", Options{Policy: Replace}, "こん�にちは"},
+		{"ISO-2022-JP skip", ISO2022JP, "\x1b$B$3$s\xff$K$A$O\x1b(B", Options{Policy: Skip}, "こんにちは"},
+		{"ISO-2022-JP-2", ISO2022JP2, "\x1b$B$3\x80$s\x80$K\x1b(B!", Options{Policy: Replace, Replacement: "〓"}, "こ〓ん〓に!"},
+		{"ISO-2022-JP-2 escape", ISO2022JP2, "\x1b$B$3\x80$s\x1b(B", Options{Policy: Escape, Escape: EscapeHex}, "こ<80>ん"},
+		{"at the start", ISO2022JP, "\xff\x1b$B$3$s\x1b(B", Options{Policy: Replace}, "�こん"},
+		{"at the end", ISO2022JP, "\x1b$B$3$s\x1b(B\xff", Options{Policy: Replace}, "こん�"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if s, err := DecodeWith(tt.enc, []byte(tt.in), tt.opts); err != nil || s != tt.want {
+				t.Errorf("DecodeWith = %q, %v; want %q", s, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeWith(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  Encoder
+		in   string
+		opts Options
+		want string
+	}{
+		{"replace", ASCII, "a€b", Options{Policy: Replace}, "a?b"},
+		{"replacement", ASCII, "a€b", Options{Policy: Replace, Replacement: "_"}, "a_b"},
+		{"skip", ASCII, "a€b", Options{Policy: Skip}, "ab"},
+		{"NCR", ASCII, "a€b", Options{Policy: Escape}, "a&#8364;b"},
+		{"Unicode escape", ASCII, "€😀", Options{Policy: Escape, Escape: EscapeUnicode}, "\\u20AC\\uD83D\\uDE00"},
+		{"hex escape", ASCII, "€", Options{Policy: Escape, Escape: EscapeHex}, "<E2><82><AC>"},
+
+		// A base character and a combining character of one JIS X 0213
+		// cell encode together.
+		{"JIS X 0213 sequence", ShiftJIS2004, "か゚😀", Options{Policy: Replace}, "\x82\xf5?"},
+		{"JIS X 0213 sequence in EUC", EUCJIS2004, "😀か゚", Options{Policy: Skip}, "\xa4\xf7"},
+		{"no sequence in CP932", CP932, "か゚", Options{Policy: Replace}, "\x82\xa9?"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if b, err := EncodeWith(tt.enc, tt.in, tt.opts); err != nil || string(b) != tt.want {
+				t.Errorf("EncodeWith = % x, %v; want % x", b, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeWithStrict(t *testing.T) {
+	tests := []struct {
+		enc    Encoder
+		in     string
+		offset int64
+		column int
+		bytes  string
+	}{
+		{ASCII, "ab€", 2, 3, "€"},
+		{ShiftJIS2004, "か゚😀", 2, 3, "😀"},
+		{CP932, "日か゚", 4, 3, "゚"},
+	}
+	for _, tt := range tests {
+		_, err := EncodeWith(tt.enc, tt.in, Options{})
+		var e *Error
+		if !errors.As(err, &e) || !errors.Is(err, ErrUnmappable) {
+			t.Errorf("EncodeWith(%q) = %v, want an *Error for %v", tt.in, err, ErrUnmappable)
+			continue
+		}
+		if e.Offset != tt.offset || e.Column != tt.column || string(e.Bytes) != tt.bytes {
+			t.Errorf("EncodeWith(%q) fails at offset %d, column %d, %q; want %d, %d, %q",
+				tt.in, e.Offset, e.Column, e.Bytes, tt.offset, tt.column, tt.bytes)
+		}
+	}
+}
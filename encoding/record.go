@@ -0,0 +1,294 @@
+package encoding
+
+import (
+	"bytes"
+	"container/list"
+	"errors"
+	"golang.org/x/text/transform"
+)
+
+// RecordSplitter is a Splitter for records that are not lines: records
+// ended by a delimiter such as NUL, records of a fixed length, or
+// paragraphs ended by blank lines. It walks the input character by
+// character with the EncodingSearcher of its encoding in the mode of
+// Validate, so it never splits inside a character, as at a trail byte of
+// Shift_JIS or a code unit of UTF-16. A RecordSplitter holds the state of
+// one input.
+type RecordSplitter struct {
+	enc   Encoding
+	es    EncodingSearcher
+	delim []byte
+	size  int
+
+	// lf and crlf are the line endings in the encoding, set in paragraph
+	// mode.
+	lf, crlf []byte
+	// lineStart is set when the walk is at the start of a line, and blank
+	// when it has passed a blank line since the start of the record.
+	lineStart, blank bool
+
+	remains []byte
+	// scanned is the length of remains already walked.
+	scanned int
+}
+
+func newRecordSplitter(enc Encoding) (*RecordSplitter, error) {
+	f, ok := enc.(SearcherFactory)
+	if !ok {
+		return nil, errors.New("encoding: " + enc.String() + " has no EncodingSearcher")
+	}
+	return &RecordSplitter{enc: enc, es: newValidator(f), lineStart: true}, nil
+}
+
+// NewSplitter returns a RecordSplitter like sp that has read no input.
+func (sp *RecordSplitter) NewSplitter() Splitter {
+	n, _ := newRecordSplitter(sp.enc)
+	n.delim, n.size = sp.delim, sp.size
+	n.lf, n.crlf = sp.lf, sp.crlf
+	return n
+}
+
+// encodeRecord returns s in enc without a byte order mark.
+func encodeRecord(enc Encoding, s string) ([]byte, error) {
+	b, err := enc.Encode(s)
+	if err != nil {
+		return nil, err
+	}
+	return trimEncodedBOM(b, enc), nil
+}
+
+// NewDelimiterSplitter returns a RecordSplitter for records that end in
+// delim, such as "\x00" for the output of find -print0. The delimiter is
+// matched in its encoding in enc, and is kept at the end of the record.
+func NewDelimiterSplitter(enc Encoding, delim string) (*RecordSplitter, error) {
+	if delim == "" {
+		return nil, errors.New("encoding: empty delimiter")
+	}
+	sp, err := newRecordSplitter(enc)
+	if err != nil {
+		return nil, err
+	}
+	if sp.delim, err = encodeRecord(enc, delim); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// NewFixedSplitter returns a RecordSplitter for records of size bytes. A
+// character that would cross the end of a record starts the next one, so
+// the record before it is shorter.
+func NewFixedSplitter(enc Encoding, size int) (*RecordSplitter, error) {
+	if size <= 0 {
+		return nil, errors.New("encoding: record size must be positive")
+	}
+	sp, err := newRecordSplitter(enc)
+	if err != nil {
+		return nil, err
+	}
+	sp.size = size
+	return sp, nil
+}
+
+// NewParagraphSplitter returns a RecordSplitter for paragraphs, records
+// that end in one or more blank lines. The blank lines are kept at the end
+// of the record.
+func NewParagraphSplitter(enc Encoding) (*RecordSplitter, error) {
+	sp, err := newRecordSplitter(enc)
+	if err != nil {
+		return nil, err
+	}
+	if sp.lf, err = encodeRecord(enc, "\n"); err != nil {
+		return nil, err
+	}
+	if sp.crlf, err = encodeRecord(enc, "\r\n"); err != nil {
+		return nil, err
+	}
+	return sp, nil
+}
+
+// maxCharSize bounds the bytes charSize tries, enough for the longest
+// character with an escape sequence in front of it.
+const maxCharSize = 8
+
+// charSize returns the size of the character, or the escape sequences and
+// the character, at the start of p. It returns 0 if p ends before the
+// character does and more input may follow.
+func (sp *RecordSplitter) charSize(p []byte, atEnd bool) int {
+	for k := 1; k <= len(p) && k <= maxCharSize; k++ {
+		n, err, _ := sp.es.EncodingSearch(p[:k], false)
+		if n > 0 {
+			return n
+		}
+		if err != transform.ErrShortSrc {
+			break
+		}
+		if k == len(p) && !atEnd {
+			return 0
+		}
+	}
+	// Step over invalid input by the bytes its lead byte takes, or by a
+	// code unit.
+	if s, ok := sp.enc.(interface{ span([]byte) int }); ok {
+		if n := s.span(p); n > 0 || !atEnd {
+			return n
+		}
+		return 1
+	}
+	if u, ok := sp.enc.(interface{ codeUnit() int }); ok && u.codeUnit() <= len(p) {
+		return u.codeUnit()
+	}
+	return 1
+}
+
+// The span methods return the bytes that the character at the start of p
+// takes by the byte ranges of the encoding, whether or not its code is
+// assigned, 1 for a byte that starts no character, or 0 if p ends before
+// the bytes that tell.
+
+func (*shiftJIS) span(p []byte) int       { return sjisSpan(p) }
+func (*cp932) span(p []byte) int          { return sjisSpan(p) }
+func (*eucJP) span(p []byte) int          { return eucSpan(p) }
+func (*eucJPMS) span(p []byte) int        { return eucSpan(p) }
+func (e *dbcsEncoding) span(p []byte) int { return dbcsSpan(p, e.four) }
+
+func (e *jis2004) span(p []byte) int {
+	if e.euc {
+		return eucSpan(p)
+	}
+	return sjisSpan(p)
+}
+
+func sjisSpan(p []byte) int {
+	if c0 := p[0]; c0 < 0x81 || 0xa0 <= c0 && c0 < 0xe0 || 0xfd <= c0 {
+		return 1
+	}
+	if len(p) < 2 {
+		return 0
+	}
+	if c1 := p[1]; 0x40 <= c1 && c1 < 0xfd && c1 != 0x7f {
+		return 2
+	}
+	return 1
+}
+
+func eucSpan(p []byte) int {
+	n := 2
+	switch c0 := p[0]; {
+	case c0 == 0x8f:
+		n = 3
+	case c0 != 0x8e && (c0 < 0xa1 || c0 == 0xff):
+		return 1
+	}
+	for k := 1; k < n; k++ {
+		if len(p) <= k {
+			return 0
+		}
+		if p[k] < 0xa1 || p[k] == 0xff {
+			return 1
+		}
+	}
+	return n
+}
+
+func dbcsSpan(p []byte, four bool) int {
+	lead := func(c byte) bool { return 0x81 <= c && c <= 0xfe }
+	digit := func(c byte) bool { return '0' <= c && c <= '9' }
+	if !lead(p[0]) {
+		return 1
+	}
+	if len(p) < 2 {
+		return 0
+	}
+	switch c1 := p[1]; {
+	case 0x40 <= c1 && c1 <= 0xfe && c1 != 0x7f:
+		return 2
+	case !four || !digit(c1):
+		return 1
+	case len(p) < 4:
+		return 0
+	case lead(p[2]) && digit(p[3]):
+		return 4
+	}
+	return 1
+}
+
+// newline returns the length of the line ending at the start of p, or 0.
+func (sp *RecordSplitter) newline(p []byte) int {
+	switch {
+	case bytes.HasPrefix(p, sp.crlf):
+		return len(sp.crlf)
+	case bytes.HasPrefix(p, sp.lf):
+		return len(sp.lf)
+	}
+	return 0
+}
+
+// partial reports whether p is a proper prefix of sep, which the next input
+// may complete.
+func partial(p, sep []byte) bool {
+	return len(p) < len(sep) && bytes.HasPrefix(sep, p)
+}
+
+func (sp *RecordSplitter) Split(src []byte, atEnd bool, lines *list.List) {
+	src = append(sp.remains, src...)
+	head, i := 0, sp.scanned
+	n := len(src)
+
+loop:
+	for i < n {
+		switch {
+		case sp.delim != nil:
+			if bytes.HasPrefix(src[i:], sp.delim) {
+				i += len(sp.delim)
+				lines.PushBack(src[head:i])
+				head = i
+				continue
+			}
+			if !atEnd && partial(src[i:], sp.delim) {
+				break loop
+			}
+
+		case sp.lf != nil:
+			if !atEnd && (partial(src[i:], sp.lf) || partial(src[i:], sp.crlf)) {
+				break loop
+			}
+			if nl := sp.newline(src[i:]); nl > 0 {
+				sp.blank = sp.blank || sp.lineStart
+				sp.lineStart = true
+				i += nl
+				continue
+			}
+			if sp.blank {
+				lines.PushBack(src[head:i])
+				head = i
+				sp.blank = false
+			}
+		}
+
+		size := sp.charSize(src[i:], atEnd)
+		if size == 0 {
+			break loop
+		}
+		if sp.size > 0 && i > head && i+size-head > sp.size {
+			lines.PushBack(src[head:i])
+			head = i
+		}
+		i += size
+		sp.lineStart = false
+		if sp.size > 0 && i-head >= sp.size {
+			lines.PushBack(src[head:i])
+			head = i
+		}
+	}
+
+	if atEnd {
+		if head < n {
+			lines.PushBack(src[head:n])
+		}
+		sp.remains, sp.scanned = nil, 0
+		sp.lineStart, sp.blank = true, false
+		return
+	}
+	sp.remains = src[head:n]
+	sp.scanned = i - head
+}
@@ -0,0 +1,77 @@
+package encoding
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestRecordSplitter(t *testing.T) {
+	delim := func(enc Encoding, d string) func() (*RecordSplitter, error) {
+		return func() (*RecordSplitter, error) { return NewDelimiterSplitter(enc, d) }
+	}
+	fixed := func(enc Encoding, size int) func() (*RecordSplitter, error) {
+		return func() (*RecordSplitter, error) { return NewFixedSplitter(enc, size) }
+	}
+	para := func(enc Encoding) func() (*RecordSplitter, error) {
+		return func() (*RecordSplitter, error) { return NewParagraphSplitter(enc) }
+	}
+	tests := []struct {
+		name    string
+		sp      func() (*RecordSplitter, error)
+		in      string
+		records []string
+	}{
+		{"NUL", delim(UTF8, "\x00"), "a.txt\x00b c\x00d", []string{"a.txt\x00", "b c\x00", "d"}},
+		{"delimiter sequence", delim(UTF8, "--"), "a--b-c--", []string{"a--", "b-c--"}},
+		{"multibyte delimiter", delim(UTF8, "→"), "a→b→", []string{"a→", "b→"}},
+		// 0x40 is "@" and the trail byte of ①, 0x87 0x40.
+		{"Shift_JIS trail byte", delim(ShiftJIS, "@"), "x\x87\x40y@z", []string{"x\x87\x40y@", "z"}},
+		{"Shift_JIS user-defined", delim(ShiftJIS, "@"), "x\xf0\x40y@z", []string{"x\xf0\x40y@", "z"}},
+		{"Shift_JIS invalid trail", delim(ShiftJIS, "@"), "x\x81 @y", []string{"x\x81 @", "y"}},
+		{"CP932 trail byte", delim(CP932, "\\"), "\x95\x5c\\a", []string{"\x95\x5c\\", "a"}},
+		{"EUC-JP", delim(EUCJP, "、"), "\xb0\xa1\xa2\xa2\xa1\xa2b", []string{"\xb0\xa1\xa2\xa2\xa1\xa2", "b"}},
+		{"GBK trail byte", delim(GBK, "@"), "\x81\x40@a", []string{"\x81\x40@", "a"}},
+		// U+0A41 U+4E00 holds the bytes of "\n" in UTF-16LE across the
+		// code units.
+		{"UTF-16", delim(UTF16LE, "\n"), "A\x00\x41\x0a\x00\x4e\n\x00b\x00", []string{"A\x00\x41\x0a\x00\x4e\n\x00", "b\x00"}},
+		{"fixed", fixed(UTF8, 3), "abcdefg", []string{"abc", "def", "g"}},
+		{"fixed Shift_JIS", fixed(ShiftJIS, 3), "ab\x87\x40c", []string{"ab", "\x87\x40c"}},
+		{"fixed UTF-16", fixed(UTF16LE, 3), "a\x00b\x00", []string{"a\x00", "b\x00"}},
+		{"paragraph", para(UTF8), "a\nb\n\n\nc\r\n\r\nd", []string{"a\nb\n\n\n", "c\r\n\r\n", "d"}},
+		{"paragraph UTF-16", para(UTF16LE), "a\x00\n\x00\n\x00b\x00", []string{"a\x00\n\x00\n\x00", "b\x00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for size := 1; size <= len(tt.in); size++ {
+				sp, err := tt.sp()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := split(sp, []byte(tt.in), size)
+				if len(got) != len(tt.records) {
+					t.Fatalf("in chunks of %d: got %q, want %q", size, got, tt.records)
+				}
+				for i := range got {
+					if !bytes.Equal(got[i], []byte(tt.records[i])) {
+						t.Errorf("in chunks of %d: record %d = % x, want % x", size, i+1, got[i], tt.records[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestRecordSplitterErrors(t *testing.T) {
+	if _, err := NewDelimiterSplitter(UTF8, ""); err == nil {
+		t.Errorf("NewDelimiterSplitter with an empty delimiter did not fail")
+	}
+	if _, err := NewDelimiterSplitter(ASCII, "→"); err == nil {
+		t.Errorf("NewDelimiterSplitter with an unencodable delimiter did not fail")
+	}
+	if _, err := NewFixedSplitter(UTF8, 0); err == nil {
+		t.Errorf("NewFixedSplitter(0) did not fail")
+	}
+	if _, err := NewParagraphSplitter(plainEncoding{}); err == nil {
+		t.Errorf("NewParagraphSplitter without an EncodingSearcher did not fail")
+	}
+}
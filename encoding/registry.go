@@ -0,0 +1,185 @@
+package encoding
+
+import (
+	"errors"
+	"sync"
+)
+
+// Entry is an encoding registered for detection.
+type Entry struct {
+	Encoding Encoding
+
+	// NewSearcher creates the searcher for one detection run. It may be
+	// nil if Encoding implements SearcherFactory.
+	NewSearcher func() EncodingSearcher
+
+	// Priority orders the entries. A valid entry wins over every entry of
+	// a larger Priority, unless its score shows the input is unlikely text
+	// in it; entries of the same Priority compete by score.
+	Priority int
+
+	// Family groups variants of one encoding, e.g. Shift JIS and its
+	// extensions. The entries of a family compete by score at the smallest
+	// Priority among them, and fall back to their own Priority on ties.
+	Family string
+
+	// Weight is the prior weight of the entry's score against the other
+	// entries of the same Priority. Zero means 1.
+	Weight float64
+}
+
+// Registry is a set of encodings that detection chooses from.
+type Registry struct {
+	mu      sync.RWMutex
+	entries []Entry
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// DefaultRegistry holds the built-in encodings. It is used by NewDetector
+// and DetectReader.
+var DefaultRegistry = newDefaultRegistry()
+
+func newDefaultRegistry() *Registry {
+	r := NewRegistry()
+	for _, e := range []Entry{
+		{Encoding: ASCII, Priority: 0},
+		{Encoding: ISO2022JP, Priority: 10},
+		{Encoding: ISO2022JP2, Priority: 15},
+		{Encoding: ISO2022JP2004, Priority: 15},
+		{Encoding: UTF8, Priority: 20},
+		{Encoding: CP932, Priority: 30, Family: "Shift_JIS"},
+		{Encoding: EUCJP, Priority: 30, Family: "EUC-JP"},
+		{Encoding: GBK, Priority: 30},
+		{Encoding: Big5, Priority: 30},
+		{Encoding: EUCKR, Priority: 30},
+		{Encoding: ShiftJIS, Priority: 35, Family: "Shift_JIS"},
+		{Encoding: GB18030, Priority: 35},
+		{Encoding: EUCJPMS, Priority: 35, Family: "EUC-JP"},
+		{Encoding: ShiftJIS2004, Priority: 38, Family: "Shift_JIS"},
+		{Encoding: EUCJIS2004, Priority: 38, Family: "EUC-JP"},
+		{Encoding: UTF16LE, Priority: 40},
+		{Encoding: UTF16BE, Priority: 40},
+		{Encoding: UTF32LE, Priority: 50},
+		{Encoding: UTF32BE, Priority: 50},
+		{Encoding: Windows1252, Priority: 90},
+		{Encoding: ISO8859_15, Priority: 90},
+		{Encoding: ISO8859_1, Priority: 90},
+		{Encoding: Windows1251, Priority: 90},
+		{Encoding: KOI8R, Priority: 90},
+	} {
+		if err := r.Register(e); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// Register adds an encoding to r, replacing any entry of the same name.
+// Entries of the same Priority keep their registration order on ties.
+func (r *Registry) Register(e Entry) error {
+	if e.Encoding == nil {
+		return errors.New("encoding: Register of nil Encoding")
+	}
+	if e.NewSearcher == nil {
+		f, ok := e.Encoding.(SearcherFactory)
+		if !ok {
+			return errors.New("encoding: Register of " + e.Encoding.String() + " without an EncodingSearcher")
+		}
+		e.NewSearcher = f.NewEncodingSearcher
+	}
+	if e.Weight == 0 {
+		e.Weight = 1
+	}
+	if e.Weight < 0 {
+		return errors.New("encoding: Register of " + e.Encoding.String() + " with a negative weight")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, old := range r.entries {
+		if old.Encoding.String() == e.Encoding.String() {
+			r.entries[i] = e
+			return nil
+		}
+	}
+	r.entries = append(r.entries, e)
+	return nil
+}
+
+// Unregister removes the encoding named name from r.
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, e := range r.entries {
+		if e.Encoding.String() == name {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return
+		}
+	}
+}
+
+// Lookup returns the registered encoding named name.
+func (r *Registry) Lookup(name string) (Encoding, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, e := range r.entries {
+		if e.Encoding.String() == name {
+			return e.Encoding, true
+		}
+	}
+	return nil, false
+}
+
+// Entries returns the registered entries in registration order.
+func (r *Registry) Entries() []Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// NewDetector returns a Detector for the registered encodings. If only is
+// not empty, detection is restricted to those encodings, and a byte order
+// mark is only recognised if one of them has the same mark. It fails if an
+// encoding of only is not registered.
+func (r *Registry) NewDetector(only ...Encoding) (*Detector, error) {
+	entries := r.Entries()
+	var encs []Encoding
+	for _, e := range entries {
+		encs = append(encs, e.Encoding)
+	}
+	for _, enc := range only {
+		if !contains(encs, enc) {
+			return nil, errors.New("encoding: " + enc.String() + " is not registered")
+		}
+	}
+
+	d := &Detector{sniffBOM: true, only: only}
+	for _, e := range entries {
+		if len(only) > 0 && !contains(only, e.Encoding) {
+			continue
+		}
+		d.checkers = append(d.checkers, &checker{
+			Entry: e,
+			es:    e.NewSearcher(),
+		})
+	}
+	return d, nil
+}
+
+func contains(encs []Encoding, enc Encoding) bool {
+	for _, e := range encs {
+		if e.String() == enc.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// Register adds an encoding to DefaultRegistry.
+func Register(e Entry) error {
+	return DefaultRegistry.Register(e)
+}
@@ -0,0 +1,128 @@
+package encoding
+
+import (
+	"container/list"
+	"testing"
+)
+
+type plainEncoding struct{}
+
+func (plainEncoding) String() string                              { return "plain" }
+func (plainEncoding) Decode(b []byte) (string, error)             { return string(b), nil }
+func (plainEncoding) Encode(s string) ([]byte, error)             { return []byte(s), nil }
+func (plainEncoding) Split(src []byte, atEnd bool, ls *list.List) {}
+
+// plainSearcher accepts any input without evidence.
+type plainSearcher struct{}
+
+func newPlainSearcher() EncodingSearcher { return plainSearcher{} }
+
+func (plainSearcher) EncodingSearch(p []byte, atEOF bool) (int, error, int) {
+	return len(p), nil, 0
+}
+
+func TestRegistryRegister(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Entry
+		ok   bool
+	}{
+		{"nil encoding", Entry{}, false},
+		{"no searcher", Entry{Encoding: ASCII, NewSearcher: nil}, true},
+		{"negative weight", Entry{Encoding: ASCII, Weight: -1}, false},
+		{"without searcher factory", Entry{Encoding: plainEncoding{}}, false},
+		{"with searcher", Entry{Encoding: plainEncoding{}, NewSearcher: ASCII.NewEncodingSearcher}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := NewRegistry().Register(tt.e)
+			if (err == nil) != tt.ok {
+				t.Errorf("Register: %v", err)
+			}
+		})
+	}
+}
+
+func TestRegistryEntries(t *testing.T) {
+	r := NewRegistry()
+	for _, e := range []Entry{
+		{Encoding: UTF8, Priority: 20},
+		{Encoding: ASCII},
+		{Encoding: UTF8, Priority: 5},
+	} {
+		if err := r.Register(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	es := r.Entries()
+	if len(es) != 2 || es[0].Encoding != UTF8 || es[0].Priority != 5 || es[1].Weight != 1 {
+		t.Fatalf("Entries() = %+v, want UTF8 replaced in place and ASCII with weight 1", es)
+	}
+	if enc, ok := r.Lookup("ASCII"); !ok || enc != ASCII {
+		t.Errorf("Lookup(ASCII) = %v, %v", enc, ok)
+	}
+	r.Unregister("ASCII")
+	if _, ok := r.Lookup("ASCII"); ok {
+		t.Errorf("ASCII still registered after Unregister")
+	}
+}
+
+func TestRegistryDetection(t *testing.T) {
+	ja := mustEncode(t, EUCJP, "日本語")
+	ja2004 := mustEncode(t, EUCJIS2004, jaText+"𠂉田さんと丂さん\n")
+	tests := []struct {
+		name    string
+		entries []Entry
+		only    []Encoding
+		in      []byte
+		want    Encoding
+	}{
+		{"priority", []Entry{{Encoding: UTF8, Priority: 10}, {Encoding: ASCII, Priority: 20}}, nil, []byte("abc"), UTF8},
+		{"registration order", []Entry{{Encoding: UTF8}, {Encoding: ASCII}}, nil, []byte("abc"), UTF8},
+		{"weight", []Entry{{Encoding: UTF8}, {Encoding: ASCII, Weight: 2}}, nil, []byte("abc"), ASCII},
+		{"only", []Entry{{Encoding: ASCII}, {Encoding: UTF8}}, []Encoding{UTF8}, []byte("abc"), UTF8},
+		{"invalid", []Entry{{Encoding: ASCII}, {Encoding: EUCJP, Priority: 30}}, nil, ja, EUCJP},
+		{"family", []Entry{{Encoding: EUCJP, Priority: 30, Family: "EUC"}, {Encoding: plainEncoding{}, NewSearcher: newPlainSearcher, Priority: 30}, {Encoding: EUCJIS2004, Priority: 38, Family: "EUC"}}, nil, ja2004, EUCJIS2004},
+		{"no family", []Entry{{Encoding: EUCJP, Priority: 30}, {Encoding: plainEncoding{}, NewSearcher: newPlainSearcher, Priority: 30}, {Encoding: EUCJIS2004, Priority: 38}}, nil, ja2004, plainEncoding{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			for _, e := range tt.entries {
+				if err := r.Register(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			d, err := r.NewDetector(tt.only...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.Feed(tt.in, true)
+			det := d.Detection()
+			if name(det.Encoding) != name(tt.want) {
+				t.Errorf("Encoding = %s, want %s", name(det.Encoding), name(tt.want))
+			}
+			if len(tt.only) > 0 && len(det.Candidates) != len(tt.only) {
+				t.Errorf("got %d candidates, want %d", len(det.Candidates), len(tt.only))
+			}
+		})
+	}
+}
+
+func TestRegistryUnknownCandidate(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register(Entry{Encoding: UTF16LE}); err != nil {
+		t.Fatal(err)
+	}
+	for _, only := range [][]Encoding{{UTF16}, {UTF16LE, ShiftJIS}} {
+		if _, err := r.NewDetector(only...); err == nil {
+			t.Errorf("NewDetector(%v) did not fail", only)
+		}
+	}
+	if _, err := NewDetector(UTF16); err == nil {
+		t.Errorf("NewDetector(UTF16) did not fail")
+	}
+	if _, _, err := DetectReader(nil, &DetectOptions{Candidates: []Encoding{UTF16}}); err == nil {
+		t.Errorf("DetectReader with an unregistered candidate did not fail")
+	}
+}
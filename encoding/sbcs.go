@@ -0,0 +1,179 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/charmap"
+	"golang.org/x/text/transform"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Single-byte code pages. Detection falls back to them when no multibyte
+// encoding fits, and tells them apart by the letters they decode to.
+var (
+	Windows1252 *sbcsEncoding = newSBCSEncoding("Windows-1252", charmap.Windows1252, latinModel)
+	ISO8859_1   *sbcsEncoding = newSBCSEncoding("ISO-8859-1", charmap.ISO8859_1, latinModel)
+	ISO8859_15  *sbcsEncoding = newSBCSEncoding("ISO-8859-15", charmap.ISO8859_15, latinModel)
+	Windows1251 *sbcsEncoding = newSBCSEncoding("Windows-1251", charmap.Windows1251, cyrillicModel)
+	KOI8R       *sbcsEncoding = newSBCSEncoding("KOI8-R", charmap.KOI8R, cyrillicModel)
+)
+
+// sbcsModel scores the characters of a single-byte code page above 0x7F
+// by how common they are in the languages that use it.
+type sbcsModel struct {
+	weight map[rune]int
+
+	// words is set where such letters make up whole words, as in Cyrillic
+	// text, rather than standing among ASCII letters, as accented Latin
+	// letters do.
+	words bool
+}
+
+// newSBCSModel weights letters, listed by falling frequency, from 3 down to
+// 1, and marks, the common symbols, 1.
+func newSBCSModel(letters, marks string, words bool) *sbcsModel {
+	m := &sbcsModel{weight: map[rune]int{}, words: words}
+	rs := []rune(letters)
+	for n, r := range rs {
+		m.weight[r] = 3 - 3*n/len(rs)
+	}
+	for _, r := range marks {
+		m.weight[r] = 1
+	}
+	return m
+}
+
+// score returns the score of r following prev. Characters the model does
+// not know count against the code page.
+func (m *sbcsModel) score(prev, r rune) int {
+	s, ok := m.weight[r]
+	if !ok {
+		return -2
+	}
+	if !unicode.IsLetter(r) || !unicode.IsLetter(prev) {
+		return s
+	}
+	if (prev >= utf8.RuneSelf) == m.words {
+		return s + 1
+	}
+	return -2
+}
+
+var latinModel = newSBCSModel(
+	"éàèáçíóüäöñúêãâõôßëïîûùœÉÀåøæìòÁÓÖÜÄÇÑÍÚÈÊÅØÆšžŠŽ",
+	" ’“”–—…‘«»°€£§©®·¿¡²³½¼¾×",
+	false)
+
+var cyrillicModel = newSBCSModel(
+	"оеаинтсрвлкмдпуяызьбгчйхжшюцщэфъёПСВКНМОАТРДГБИЕЛЗФЭУЯХЧШЖЦЮЙЩЁЫЬЪ",
+	" ’“”–—…‘«»°№§©®·",
+	true)
+
+type sbcsEncoding struct {
+	*splitter
+
+	name  string
+	cm    *charmap.Charmap
+	model *sbcsModel
+
+	once   sync.Once
+	decode [256]rune
+	encode map[rune]byte
+}
+
+func newSBCSEncoding(name string, cm *charmap.Charmap, model *sbcsModel) *sbcsEncoding {
+	return &sbcsEncoding{
+		splitter: &splitter{},
+		name:     name,
+		cm:       cm,
+		model:    model,
+	}
+}
+
+func (e *sbcsEncoding) String() string {
+	return e.name
+}
+
+func (e *sbcsEncoding) NewEncodingSearcher() EncodingSearcher {
+	return &sbcsDecoder{e: e}
+}
+
+func (e *sbcsEncoding) newValidator() EncodingSearcher {
+	return &sbcsDecoder{e: e, validate: true}
+}
+
+func (e *sbcsEncoding) init() {
+	e.once.Do(func() {
+		e.encode = make(map[rune]byte)
+		d := e.cm.NewDecoder()
+		for i := range e.decode {
+			d.Reset()
+			dst, _, err := transform.Bytes(d, []byte{byte(i)})
+			r, size := utf8.DecodeRune(dst)
+			if err != nil || size != len(dst) {
+				r = RuneError
+			}
+			e.decode[i] = r
+			if _, ok := e.encode[r]; !ok && r != RuneError {
+				e.encode[r] = byte(i)
+			}
+		}
+	})
+}
+
+// sbcsDecoder rejects NUL bytes and bytes that are unassigned in the code
+// page, and scores the rest with its model, which counts C1 controls other
+// than NEL against it. With validate set, it only rejects unassigned bytes.
+type sbcsDecoder struct {
+	e        *sbcsEncoding
+	prev     rune
+	validate bool
+}
+
+func (d *sbcsDecoder) EncodingSearch(p []byte, atEOF bool) (nSrc int, err error, score int) {
+	d.e.init()
+	for ; nSrc < len(p); nSrc++ {
+		c0 := p[nSrc]
+		r := d.e.decode[c0]
+		switch {
+		case r == RuneError, !d.validate && c0 == 0x00:
+			return nSrc, ErrInvalidEncoding, score
+		case r == '\u0085':
+			// NEL breaks lines.
+		case c0 >= utf8.RuneSelf:
+			score += d.e.model.score(d.prev, r)
+		}
+		d.prev = r
+	}
+	return nSrc, nil, score
+}
+
+// Decode reads unassigned bytes as RuneError, and returns
+// ErrInvalidEncoding with the text.
+func (e *sbcsEncoding) Decode(b []byte) (string, error) {
+	e.init()
+	var buf strings.Builder
+	var err error
+	for _, c0 := range b {
+		r := e.decode[c0]
+		if r == RuneError {
+			err = ErrInvalidEncoding
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String(), err
+}
+
+func (e *sbcsEncoding) Encode(s string) ([]byte, error) {
+	e.init()
+	ret := make([]byte, 0, len(s))
+	for _, r := range s {
+		c, ok := e.encode[r]
+		if !ok {
+			return nil, ErrUnmappable
+		}
+		ret = append(ret, c)
+	}
+	return ret, nil
+}
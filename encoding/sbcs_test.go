@@ -0,0 +1,78 @@
+package encoding
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSBCS(t *testing.T) {
+	tests := []struct {
+		enc *sbcsEncoding
+		s   string
+		b   string
+	}{
+		{Windows1252, "Größe €", "Gr\xf6\xdfe \x80"},
+		{ISO8859_1, "Größe", "Gr\xf6\xdfe"},
+		{ISO8859_15, "€œ", "\xa4\xbd"},
+		{Windows1251, "Привет", "\xcf\xf0\xe8\xe2\xe5\xf2"},
+		{KOI8R, "Привет", "\xf0\xd2\xc9\xd7\xc5\xd4"},
+	}
+	for _, tt := range tests {
+		if b := mustEncode(t, tt.enc, tt.s); string(b) != tt.b {
+			t.Errorf("%s: Encode(%q) = % x, want % x", tt.enc, tt.s, b, tt.b)
+		}
+		if s, err := tt.enc.Decode([]byte(tt.b)); s != tt.s || err != nil {
+			t.Errorf("%s: Decode(% x) = %q, %v; want %q", tt.enc, tt.b, s, err, tt.s)
+		}
+	}
+	if _, err := Windows1252.Encode("日本"); !errors.Is(err, ErrUnmappable) {
+		t.Errorf("Windows-1252: Encode of Japanese: %v, want ErrUnmappable", err)
+	}
+}
+
+func TestASCII(t *testing.T) {
+	if b, err := ASCII.Encode("abc\n"); string(b) != "abc\n" || err != nil {
+		t.Errorf("Encode(abc) = %q, %v", b, err)
+	}
+	for _, s := range []string{"é", "日本", "\u0080"} {
+		if _, err := ASCII.Encode(s); !errors.Is(err, ErrUnmappable) {
+			t.Errorf("Encode(%q): %v, want ErrUnmappable", s, err)
+		}
+	}
+	for _, in := range []string{"a\xe9", "a\x1b$B", "a\x00"} {
+		if ok, _ := CheckEncodingOf(chunks([]byte(in), 2), ASCII); ok {
+			t.Errorf("CheckEncodingOf(%q) = true", in)
+		}
+	}
+}
+
+func TestSBCSSearcher(t *testing.T) {
+	tests := []struct {
+		enc   *sbcsEncoding
+		in    string
+		valid bool
+	}{
+		{ISO8859_1, "Zeile eins\x85Zeile zwei\x85", true}, // NEL
+		{ISO8859_1, "a\x81b", true},                       // other C1 controls
+		{Windows1252, "a\x85b", true},                     // an ellipsis
+		{Windows1252, "a\x81b", false},
+		{ISO8859_1, "a\x00b", false},
+	}
+	for _, tt := range tests {
+		if ok, _ := CheckEncodingOf(chunks([]byte(tt.in), 3), tt.enc); ok != tt.valid {
+			t.Errorf("%s: CheckEncodingOf(%q) = %v, want %v", tt.enc, tt.in, ok, tt.valid)
+		}
+	}
+
+	// Among the Latin code pages, text with C1 controls is Latin-1 where
+	// Windows-1252 does not fit, and NEL costs nothing.
+	det := detect(t, []byte("Gr\xfc\xdfe\x85aus K\xf6ln\x85\x81"), Windows1252, ISO8859_1, ISO8859_15)
+	if det.Encoding != ISO8859_1 {
+		t.Errorf("detected as %s, want %s", name(det.Encoding), ISO8859_1)
+	}
+	_, nel := CheckEncodingOf(chunks([]byte("K\xf6ln\x85"), 8), ISO8859_1)
+	_, lf := CheckEncodingOf(chunks([]byte("K\xf6ln\n"), 8), ISO8859_1)
+	if nel != lf {
+		t.Errorf("score with NEL %d, with LF %d", nel, lf)
+	}
+}
@@ -0,0 +1,213 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// EncoderSession encodes a document that is written to it in pieces, such
+// as lines, as one stream. It writes the byte order mark at most once, at
+// the start, and does not return an ISO-2022 encoding to ASCII between two
+// pieces that continue in the same character set.
+type EncoderSession struct {
+	w    io.Writer
+	enc  Encoder
+	opts Options
+
+	bom     []byte
+	started bool
+	shifts  bool
+
+	// pending holds an incomplete UTF-8 sequence at the end of the last
+	// Write.
+	pending []byte
+	// reset holds the escape back to ASCII that ended the last piece, and
+	// shift the designation it undid.
+	reset, shift []byte
+
+	offset    int64
+	line, col int
+	cr        bool
+	err       error
+}
+
+// NewEncoderSession returns an EncoderSession that writes the encoding of
+// the UTF-8 text written to it to w. It writes a byte order mark if bom is
+// set and enc has one, and handles unencodable characters by opts.
+func NewEncoderSession(w io.Writer, enc Encoder, bom bool, opts Options) *EncoderSession {
+	s := &EncoderSession{w: w, enc: enc, opts: opts, line: 1}
+	if bm, ok := enc.(ByteOrderMarker); ok {
+		s.bom = bm.BOM()
+		s.started = !bom
+	} else {
+		s.started = true
+	}
+	switch enc.(type) {
+	case *iso2022JPEncoding, *iso2022Encoding:
+		s.shifts = true
+	}
+	return s
+}
+
+// Write encodes p, which may end in the middle of a UTF-8 sequence. Errors
+// are *Error with the position in the document.
+func (s *EncoderSession) Write(p []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	b := append(s.pending, p...)
+	n := len(b)
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				n = i
+			}
+			break
+		}
+	}
+	s.pending = append([]byte(nil), b[n:]...)
+	if err := s.encode(string(b[:n])); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// WriteString encodes str.
+func (s *EncoderSession) WriteString(str string) (int, error) {
+	return s.Write([]byte(str))
+}
+
+func (s *EncoderSession) encode(str string) error {
+	if str == "" {
+		return nil
+	}
+	if err := s.start(); err != nil {
+		return err
+	}
+	b, err := EncodeWith(s.enc, str, s.opts)
+	if err != nil {
+		e := err.(*Error)
+		e.Offset += s.offset
+		if e.Line == 1 {
+			e.Column += s.col
+		}
+		e.Line += s.line - 1
+		s.err = e
+		return e
+	}
+	// Encoders that expect a byte order mark put one in front of every
+	// call.
+	b = trimEncodedBOM(b, s.enc)
+
+	if s.shifts {
+		switch d := firstDesignation(b); {
+		case s.reset == nil:
+		case d != nil && bytes.Equal(d, s.shift):
+			b = b[len(d):]
+		case d != nil:
+			// The piece designates another set anyway.
+		default:
+			if err := s.write(s.reset); err != nil {
+				return err
+			}
+		}
+		s.reset = nil
+		if bytes.HasSuffix(b, asciiDesignation) {
+			b = b[:len(b)-len(asciiDesignation)]
+			s.reset = asciiDesignation
+			s.shift = lastDesignation(b)
+		}
+	}
+	if err := s.write(b); err != nil {
+		return err
+	}
+	s.count(str)
+	return nil
+}
+
+var errIncompleteUTF8 = fmt.Errorf("%w: incomplete UTF-8 sequence", ErrInvalidEncoding)
+
+var asciiDesignation = []byte(iso2022Designations[csASCII])
+
+// firstDesignation returns the G0 designation b starts with, or nil.
+func firstDesignation(b []byte) []byte {
+	for set := csASCII; set < csLatin1; set++ {
+		if d := iso2022Designations[set]; bytes.HasPrefix(b, []byte(d)) {
+			return []byte(d)
+		}
+	}
+	return nil
+}
+
+// lastDesignation returns the last G0 designation in b.
+func lastDesignation(b []byte) []byte {
+	for i := bytes.LastIndexByte(b, asciiEsc); i >= 0; i = bytes.LastIndexByte(b[:i], asciiEsc) {
+		if d := firstDesignation(b[i:]); d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+// start writes the byte order mark if it is due.
+func (s *EncoderSession) start() error {
+	if s.started {
+		return nil
+	}
+	s.started = true
+	return s.write(s.bom)
+}
+
+// count advances the line and the column past str.
+func (s *EncoderSession) count(str string) {
+	for _, r := range str {
+		switch {
+		case r == '\n' && s.cr:
+		case r == '\n' || r == '\r':
+			s.line++
+			s.col = 0
+		default:
+			s.col++
+		}
+		s.cr = r == '\r'
+	}
+}
+
+func (s *EncoderSession) write(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	n, err := s.w.Write(b)
+	s.offset += int64(n)
+	if err != nil {
+		s.err = err
+	}
+	return err
+}
+
+// Close ends the document, returning a stateful encoding to its initial
+// state. It does not close the underlying writer.
+func (s *EncoderSession) Close() error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.pending) > 0 {
+		s.err = &Error{
+			Offset:   s.offset,
+			Line:     s.line,
+			Column:   s.col + 1,
+			Bytes:    s.pending,
+			Encoding: encoderName(s.enc),
+			Err:      errIncompleteUTF8,
+		}
+		return s.err
+	}
+	if err := s.start(); err != nil {
+		return err
+	}
+	err := s.write(s.reset)
+	s.reset = nil
+	return err
+}
@@ -0,0 +1,101 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestEncoderSession(t *testing.T) {
+	const doc = "日本\n語\nab日本x"
+	tests := []struct {
+		name string
+		enc  Encoder
+		bom  bool
+		want string
+	}{
+		{"ISO-2022-JP", ISO2022JP, false, "\x1b$BF|K\\\x1b(B\n\x1b$B8l\x1b(B\nab\x1b$BF|K\\\x1b(Bx"},
+		{"ISO-2022-JP-2", ISO2022JP2, false, "\x1b$BF|K\\\x1b(B\n\x1b$B8l\x1b(B\nab\x1b$BF|K\\\x1b(Bx"},
+		{"UTF-16 with BOM", UTF16, true, "\xff\xfe" + "\xe5\x65\x2c\x67\n\x00\x9e\x8a\n\x00a\x00b\x00\xe5\x65\x2c\x67x\x00"},
+		{"UTF-16 without BOM", UTF16, false, "\xe5\x65\x2c\x67\n\x00\x9e\x8a\n\x00a\x00b\x00\xe5\x65\x2c\x67x\x00"},
+		{"UTF-8 with BOM", UTF8, true, "\xef\xbb\xbf" + doc},
+		{"Shift_JIS without a BOM", ShiftJIS, true, "\x93\xfa\x96\x7b\n\x8c\xea\nab\x93\xfa\x96\x7bx"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// However the document is written, in lines or byte by byte,
+			// it encodes as one stream.
+			for _, size := range []int{1, 2, 3, 5, len(doc)} {
+				var buf bytes.Buffer
+				s := NewEncoderSession(&buf, tt.enc, tt.bom, Options{})
+				for p := []byte(doc); len(p) > 0; {
+					n := size
+					if n > len(p) {
+						n = len(p)
+					}
+					if _, err := s.Write(p[:n]); err != nil {
+						t.Fatal(err)
+					}
+					p = p[n:]
+				}
+				if err := s.Close(); err != nil {
+					t.Fatal(err)
+				}
+				if buf.String() != tt.want {
+					t.Errorf("in pieces of %d bytes: % x, want % x", size, buf.Bytes(), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderSessionEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewEncoderSession(&buf, UTF16, true, Options{}).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "\xff\xfe" {
+		t.Errorf("empty document = % x, want the byte order mark", buf.Bytes())
+	}
+}
+
+func TestEncoderSessionErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		pieces []string
+		err    error
+		line   int
+		column int
+		offset int64
+	}{
+		{"unmappable", []string{"ab\r\n", "cd\n", "e☃"}, ErrUnmappable, 3, 2, 8},
+		{"unmappable in a piece", []string{"a", "b☃"}, ErrUnmappable, 1, 3, 2},
+		{"incomplete UTF-8", []string{"ab\n", "c\xe6\x97"}, ErrInvalidEncoding, 2, 2, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			s := NewEncoderSession(&buf, ASCII, false, Options{})
+			var err error
+			for _, p := range tt.pieces {
+				if _, err = s.WriteString(p); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = s.Close()
+			}
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want an *Error for %v", err, tt.err)
+			}
+			if e.Line != tt.line || e.Column != tt.column || e.Offset != tt.offset {
+				t.Errorf("error at line %d, column %d, offset %d; want %d, %d, %d", e.Line, e.Column, e.Offset, tt.line, tt.column, tt.offset)
+			}
+			// The session stays failed.
+			if _, err := s.WriteString("x"); err != e {
+				t.Errorf("Write after the error = %v, want %v", err, e)
+			}
+		})
+	}
+}
@@ -3,16 +3,22 @@ package encoding
 import (
 	"bytes"
 	"code.google.com/p/go.text/encoding/japanese"
+	"fmt"
 	"golang.org/x/text/transform"
 	"io/ioutil"
 	"strings"
 	"unicode/utf8"
 )
 
+// ShiftJIS is Shift JIS restricted to JIS X 0208 when detecting; files
+// that use the Windows vendor extensions are detected as CP932.
 var ShiftJIS *shiftJIS = newShiftJIS()
 
+// ErrVendorExtension is returned by the Shift JIS searcher for characters
+// that only CP932 has.
+var ErrVendorExtension = fmt.Errorf("%w: vendor extension, requires CP932", ErrInvalidEncoding)
+
 type shiftJIS struct {
-	shiftJISDecoder
 	*splitter
 
 	decoder transform.Transformer
@@ -24,34 +30,62 @@ func (shiftJIS) String() string {
 }
 
 func (shiftJIS) NewEncodingSearcher() EncodingSearcher {
-	return shiftJISDecoder{}
+	return newShiftJISDecoder()
+}
+
+// EncodingSearch checks src with a fresh searcher, so that ShiftJIS is an
+// EncodingSearcher itself; a run over several chunks takes the searcher of
+// NewEncodingSearcher, which scores across them.
+func (e shiftJIS) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	return e.NewEncodingSearcher().EncodingSearch(src, atEOF)
+}
+
+func (shiftJIS) newValidator() EncodingSearcher {
+	d := newShiftJISDecoder()
+	d.validate = true
+	return d
 }
 
 func newShiftJIS() *shiftJIS {
 	return &shiftJIS{
-		shiftJISDecoder: shiftJISDecoder{},
-		splitter:        &splitter{},
+		splitter: &splitter{},
 	}
 }
 
-type shiftJISDecoder struct{ transform.NopResetter }
+// shiftJISDecoder scores the characters it validates by how typical they
+// are of Japanese text. With validate set, it accepts what Decode reads:
+// NUL bytes, 0x80 and the CP932 extensions but user-defined characters.
+type shiftJISDecoder struct {
+	transform.NopResetter
+	lm       lmScorer
+	validate bool
+}
+
+func newShiftJISDecoder() *shiftJISDecoder {
+	return &shiftJISDecoder{lm: lmScorer{m: jaModel}}
+}
 
-func (shiftJISDecoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+func (d *shiftJISDecoder) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
 	//r, size := rune(0), 0
 	size := 0
 loop:
 	for ; nSrc < len(src); nSrc += size {
 		switch c0 := src[nSrc]; {
-		case c0 == 0x00:
+		case c0 == 0x00 && !d.validate:
 			err = ErrInvalidEncoding
 			break loop
 		case c0 < utf8.RuneSelf:
 			//r, size = rune(c0), 1
 			size = 1
+			d.lm.add(rune(c0), size)
+
+		case c0 == 0x80 && d.validate:
+			size = 1
 
 		case 0xa1 <= c0 && c0 < 0xe0:
 			//r, size = rune(c0)+(0xff61-0xa1), 1
 			size = 1
+			d.lm.add(rune(c0)+(0xff61-0xa1), size)
 
 		case (0x81 <= c0 && c0 < 0xa0) || (0xe0 <= c0 && c0 < 0xf0):
 			if c0 <= 0x9f {
@@ -85,15 +119,42 @@ loop:
 				err = ErrInvalidEncoding
 				break loop
 			}
-			//r, size = '\ufffd', 2
-			score++
+			// JIS X 0208 leaves rows 9-15 and 85-94 unassigned; CP932
+			// puts its extensions in rows 13 and 89-92.
+			switch row := int(c0) + 1; {
+			case row == 13 || 89 <= row && row <= 92:
+				if !d.validate {
+					err = ErrVendorExtension
+					break loop
+				}
+			case 9 <= row && row <= 15 || 85 <= row:
+				err = ErrInvalidEncoding
+				break loop
+			}
+			r := jis0208Rune(int(c0)*94 + int(c1))
+			if r == RuneError {
+				err = ErrInvalidEncoding
+				break loop
+			}
+			size = 2
+			d.lm.add(r, size)
+
+		case 0xf0 <= c0 && c0 < 0xfd:
+			if !d.validate || c0 < 0xfa {
+				err = ErrVendorExtension
+				break loop
+			}
+			if nSrc+1 >= len(src) {
+				err = transform.ErrShortSrc
+				break loop
+			}
+			r := cp932Rune(c0, src[nSrc+1])
+			if r == RuneError {
+				err = ErrInvalidEncoding
+				break loop
+			}
 			size = 2
-			//			if i := int(c0)*94 + int(c1); i < len(jis0208Decode) {
-			//				r = rune(jis0208Decode[i])
-			//				if r == 0 {
-			//					r = '\ufffd'
-			//				}
-			//			}
+			d.lm.add(r, size)
 
 		default:
 			err = ErrInvalidEncoding
@@ -109,7 +170,7 @@ loop:
 	if atEOF && err == transform.ErrShortSrc {
 		err = ErrInvalidEncoding
 	}
-	return nSrc, err, score
+	return nSrc, err, d.lm.take()
 }
 
 func (c *shiftJIS) getDecoder() transform.Transformer {
@@ -0,0 +1,93 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ErrShiftState is returned for a line of an ISO-2022 encoding that does
+// not return to ASCII before its end, as RFC 1468 requires.
+var ErrShiftState = fmt.Errorf("%w: line ends in a non-ASCII shift state", ErrInvalidEncoding)
+
+// LineDecoder decodes the lines of one document in order, carrying the
+// shift state of a stateful encoding from each line to the next, for
+// producers that do not return to ASCII before a newline.
+type LineDecoder interface {
+	Decoder
+	Reset()
+}
+
+// LineDecoderFactory is implemented by the stateful encodings.
+type LineDecoderFactory interface {
+	NewLineDecoder() LineDecoder
+}
+
+// iso2022JPLines reads ISO-2022-JP with the sets that its EncodingSearcher
+// accepts, for the decoding that the x/text decoder does not offer.
+var iso2022JPLines = newIso2022Encoding("ISO2022", map[string]iso2022Set{
+	"\x1b(B":  csASCII,
+	"\x1b(J":  csRoman,
+	"\x1b(I":  csKatakana,
+	"\x1b$@":  csJIS0208,
+	"\x1b$B":  csJIS0208,
+	"\x1b$(D": csJIS0212,
+}, nil)
+
+func (c *iso2022JPEncoding) NewLineDecoder() LineDecoder {
+	return iso2022JPLines.NewLineDecoder()
+}
+
+func (e *iso2022Encoding) NewLineDecoder() LineDecoder {
+	d := e.newDecoder()
+	d.carry = true
+	return &iso2022LineDecoder{d}
+}
+
+type iso2022LineDecoder struct {
+	d *iso2022Decoder
+}
+
+func (d *iso2022LineDecoder) Decode(b []byte) (string, error) {
+	return d.d.decode(b)
+}
+
+func (d *iso2022LineDecoder) Reset() {
+	d.d.Reset()
+}
+
+// iso2022Of returns the ISO-2022 reading of enc.
+func iso2022Of(enc Encoding) (*iso2022Encoding, bool) {
+	switch e := enc.(type) {
+	case *iso2022Encoding:
+		return e, true
+	case *iso2022JPEncoding:
+		return iso2022JPLines, true
+	}
+	return nil, false
+}
+
+// ValidateShiftState checks that line, of an ISO-2022 encoding, returns to
+// ASCII before its line ending. It returns an *Error for ErrShiftState at
+// the line ending, or at the end of a line without one. Other encodings are
+// not checked.
+func ValidateShiftState(enc Encoding, line []byte) error {
+	e, ok := iso2022Of(enc)
+	if !ok {
+		return nil
+	}
+	body := bytes.TrimRight(line, "\r\n")
+	d := e.newDecoder()
+	d.carry = true
+	s, _ := d.decode(body)
+	if d.g0 == csASCII || d.g0 == csRoman {
+		return nil
+	}
+	return &Error{
+		Offset:   int64(len(body)),
+		Line:     1,
+		Column:   len([]rune(s)) + 1,
+		Bytes:    append([]byte(nil), line[len(body):]...),
+		Encoding: enc.String(),
+		Err:      ErrShiftState,
+	}
+}
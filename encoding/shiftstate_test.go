@@ -0,0 +1,77 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestLineDecoder(t *testing.T) {
+	tests := []struct {
+		name  string
+		enc   Encoding
+		lines []string
+		want  []string
+	}{
+		{"ISO-2022-JP", ISO2022JP, []string{"\x1b$BF|\n", "K\\\x1b(B\n", "a\n"}, []string{"日\n", "本\n", "a\n"}},
+		{"ISO-2022-JP-2", ISO2022JP2, []string{"\x1b$BF|\r\n", "K\\\x1b(B\r\n"}, []string{"日\r\n", "本\r\n"}},
+		{"JIS X 0201 Roman", ISO2022JP, []string{"\x1b(J\\\n", "~\x1b(B\n"}, []string{"¥\n", "‾\n"}},
+		{"returns to ASCII", ISO2022JP, []string{"\x1b$BF|\x1b(B\n", "K\\\n"}, []string{"日\n", "K\\\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.enc.(LineDecoderFactory).NewLineDecoder()
+			for i, l := range tt.lines {
+				s, err := d.Decode([]byte(l))
+				if err != nil || s != tt.want[i] {
+					t.Errorf("line %d = %q, %v; want %q", i+1, s, err, tt.want[i])
+				}
+			}
+		})
+	}
+
+	// Reset returns to ASCII.
+	d := ISO2022JP.NewLineDecoder()
+	d.Decode([]byte("\x1b$BF|\n"))
+	d.Reset()
+	if s, err := d.Decode([]byte("K\\\n")); err != nil || s != "K\\\n" {
+		t.Errorf("after Reset: %q, %v; want %q", s, err, "K\\\n")
+	}
+}
+
+func TestValidateShiftState(t *testing.T) {
+	tests := []struct {
+		name   string
+		enc    Encoding
+		line   string
+		offset int64 // -1 if valid
+		column int
+		bytes  string
+	}{
+		{"ASCII at the end", ISO2022JP, "\x1b$BF|\x1b(B\n", -1, 0, ""},
+		{"Roman at the end", ISO2022JP, "\x1b(JA\n", -1, 0, ""},
+		{"plain", ISO2022JP2, "abc\n", -1, 0, ""},
+		{"other encoding", UTF8, "\x1b$BF|\n", -1, 0, ""},
+		{"shifted at LF", ISO2022JP, "\x1b$BF|\n", 5, 2, "\n"},
+		{"shifted at CRLF", ISO2022JP2, "a\x1b$BF|K\\\r\n", 8, 4, "\r\n"},
+		{"shifted without a line ending", ISO2022JP2004, "\x1b$(QF|", 6, 2, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateShiftState(tt.enc, []byte(tt.line))
+			if tt.offset < 0 {
+				if err != nil {
+					t.Errorf("ValidateShiftState: %v", err)
+				}
+				return
+			}
+			var e *Error
+			if !errors.As(err, &e) || !errors.Is(err, ErrShiftState) || !errors.Is(err, ErrInvalidEncoding) {
+				t.Fatalf("ValidateShiftState = %v, want an *Error for %v", err, ErrShiftState)
+			}
+			if e.Offset != tt.offset || e.Column != tt.column || !bytes.Equal(e.Bytes, []byte(tt.bytes)) {
+				t.Errorf("error at offset %d, column %d, % x; want %d, %d, % x", e.Offset, e.Column, e.Bytes, tt.offset, tt.column, tt.bytes)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package encoding
+
+import (
+	"bytes"
+	"io"
+)
+
+// DefaultMaxSample is the number of bytes DetectReader reads at most when
+// DetectOptions.MaxSample is zero.
+const DefaultMaxSample = 64 * 1024
+
+// DefaultMinEvidence is the score the leading candidate needs before
+// DetectReader stops early when DetectOptions.MinEvidence is zero.
+const DefaultMinEvidence = 32
+
+const defaultChunkSize = 4096
+
+// DetectOptions controls DetectReader.
+type DetectOptions struct {
+	// MaxSample limits the bytes read from the input.
+	MaxSample int
+
+	// ChunkSize is the size of each read.
+	ChunkSize int
+
+	// MinEvidence is the score the leading candidate must reach,
+	// clearly ahead of its rivals, before reading stops early.
+	MinEvidence int
+
+	// Registry supplies the candidates. Nil means DefaultRegistry.
+	Registry *Registry
+
+	// Candidates restricts detection to these encodings if not empty.
+	Candidates []Encoding
+}
+
+func (o *DetectOptions) withDefaults() DetectOptions {
+	var opts DetectOptions
+	if o != nil {
+		opts = *o
+	}
+	if opts.MaxSample <= 0 {
+		opts.MaxSample = DefaultMaxSample
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = defaultChunkSize
+	}
+	if opts.ChunkSize > opts.MaxSample {
+		opts.ChunkSize = opts.MaxSample
+	}
+	if opts.MinEvidence <= 0 {
+		opts.MinEvidence = DefaultMinEvidence
+	}
+	if opts.Registry == nil {
+		opts.Registry = DefaultRegistry
+	}
+	return opts
+}
+
+// DetectReader detects the encoding of r from a bounded prefix of it.
+// Reading stops at opts.MaxSample bytes, at the end of r, or as soon as
+// the evidence is decisive. The returned reader replays the sniffed bytes
+// followed by the rest of r. opts may be nil.
+func DetectReader(r io.Reader, opts *DetectOptions) (*Detection, io.Reader, error) {
+	o := opts.withDefaults()
+	d, err := o.Registry.NewDetector(o.Candidates...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var sample bytes.Buffer
+	buf := make([]byte, o.ChunkSize)
+	for sample.Len() < o.MaxSample {
+		b := buf
+		if rest := o.MaxSample - sample.Len(); rest < len(b) {
+			b = b[:rest]
+		}
+		var n int
+		n, err = io.ReadFull(r, b)
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		sample.Write(b[:n])
+		d.Feed(b[:n], err == io.EOF)
+		if err != nil || d.decisive(o.MinEvidence) {
+			break
+		}
+	}
+
+	replay := io.MultiReader(bytes.NewReader(sample.Bytes()), r)
+	if err == io.EOF {
+		err = nil
+	}
+	return d.Detection(), replay, err
+}
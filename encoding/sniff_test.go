@@ -0,0 +1,75 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+func TestDetectReader(t *testing.T) {
+	sjis := mustEncode(t, ShiftJIS, strings.Repeat(jaText, 2000))
+	tests := []struct {
+		name     string
+		in       []byte
+		opts     *DetectOptions
+		want     Encoding
+		maxRead  int
+		readsAll bool
+	}{
+		{"stops once decisive", sjis, nil, CP932, DefaultMaxSample, false},
+		{"max sample", []byte(strings.Repeat("abc\n", 1000)), &DetectOptions{MaxSample: 100, ChunkSize: 30}, ASCII, 100, false},
+		{"short input", []byte("abc\n"), nil, ASCII, 4, true},
+		{"candidates", sjis, &DetectOptions{Candidates: []Encoding{EUCJP, ShiftJIS}}, ShiftJIS, DefaultMaxSample, false},
+		{"byte order mark", append([]byte{0xff, 0xfe}, mustEncode(t, UTF16LE, "abc\n")...), nil, UTF16, DefaultMaxSample, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &countingReader{r: bytes.NewReader(tt.in)}
+			det, r, err := DetectReader(cr, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name(det.Encoding) != name(tt.want) {
+				t.Errorf("Encoding = %s, want %s", name(det.Encoding), name(tt.want))
+			}
+			if cr.n > tt.maxRead {
+				t.Errorf("read %d bytes, want at most %d", cr.n, tt.maxRead)
+			}
+			if tt.readsAll != (cr.n == len(tt.in)) {
+				t.Errorf("read %d of %d bytes", cr.n, len(tt.in))
+			}
+			all, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(all, tt.in) {
+				t.Errorf("replay returned %d bytes, %v; want the whole input", len(all), err)
+			}
+		})
+	}
+}
+
+type failingReader struct{}
+
+var errRead = errors.New("read failed")
+
+func (failingReader) Read(p []byte) (int, error) {
+	return copy(p, "abc"), errRead
+}
+
+func TestDetectReaderError(t *testing.T) {
+	_, _, err := DetectReader(failingReader{}, nil)
+	if err != errRead {
+		t.Errorf("err = %v, want %v", err, errRead)
+	}
+}
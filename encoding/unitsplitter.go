@@ -0,0 +1,78 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/unicode"
+	"container/list"
+)
+
+// unitSplitter splits lines of an encoding with fixed-size code units,
+// such as UTF-16 and UTF-32. It only breaks lines at whole code units.
+type unitSplitter struct {
+	size   int
+	endian unicode.Endianness
+
+	remains []byte
+	// scanned is the length of remains already searched for line ends.
+	scanned int
+}
+
+func (sp *unitSplitter) NewSplitter() Splitter {
+	return &unitSplitter{size: sp.size, endian: sp.endian}
+}
+
+func (sp *unitSplitter) reset() {
+	sp.remains = []byte{}
+	sp.scanned = 0
+}
+
+func (sp *unitSplitter) unit(b []byte) uint32 {
+	var u uint32
+	if sp.endian == unicode.BigEndian {
+		for i := 0; i < sp.size; i++ {
+			u = u<<8 | uint32(b[i])
+		}
+	} else {
+		for i := sp.size - 1; i >= 0; i-- {
+			u = u<<8 | uint32(b[i])
+		}
+	}
+	return u
+}
+
+func (sp *unitSplitter) Split(src []byte, atEnd bool, lines *list.List) {
+	start := sp.scanned
+	src = append(sp.remains, src...)
+	sp.reset()
+
+	n := len(src)
+	nHead := 0
+	i := start
+loop:
+	for ; i+sp.size <= n; i += sp.size {
+		switch sp.unit(src[i:]) {
+		case uint32(LE):
+			lines.PushBack(src[nHead : i+sp.size])
+			nHead = i + sp.size
+		case uint32(CR):
+			if n < i+2*sp.size {
+				if !atEnd {
+					// wait for the next unit, it may be LF.
+					break loop
+				}
+			} else if sp.unit(src[i+sp.size:]) == uint32(LE) {
+				i += sp.size
+			}
+			lines.PushBack(src[nHead : i+sp.size])
+			nHead = i + sp.size
+		}
+	}
+
+	if nHead < n {
+		if atEnd {
+			lines.PushBack(src[nHead:n])
+		} else {
+			sp.remains = src[nHead:n]
+			sp.scanned = i - nHead
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package encoding
+
+import (
+	"bytes"
+	"code.google.com/p/go.text/encoding/unicode"
+	"container/list"
+	"testing"
+)
+
+func split(sp Splitter, in []byte, size int) [][]byte {
+	ls := list.New()
+	for len(in) > size {
+		sp.Split(in[:size], false, ls)
+		in = in[size:]
+	}
+	sp.Split(in, true, ls)
+	var out [][]byte
+	for e := ls.Front(); e != nil; e = e.Next() {
+		out = append(out, e.Value.([]byte))
+	}
+	return out
+}
+
+func TestUnitSplitter(t *testing.T) {
+	tests := []struct {
+		enc   Encoding
+		size  int
+		e     unicode.Endianness
+		lines []string
+	}{
+		{UTF16LE, 2, unicode.LittleEndian, []string{"a\r\n", "b\n", "c\r", "d"}},
+		{UTF16BE, 2, unicode.BigEndian, []string{"a\r\n", "b\n", "c\r", "d"}},
+		// U+0A0D and U+0D0A hold the bytes of CR and LF.
+		{UTF16LE, 2, unicode.LittleEndian, []string{"\u0a0d\u0d0a\n", "x"}},
+		{UTF16BE, 2, unicode.BigEndian, []string{"\u0a0d\u0d0a\n", "x"}},
+		{UTF32LE, 4, unicode.LittleEndian, []string{"a\r\n", "\u0a0d\n", "\r", "\r\n"}},
+		{UTF32BE, 4, unicode.BigEndian, []string{"a\r\n", "\u0a0d\n", "\r", "\r\n"}},
+	}
+	for _, tt := range tests {
+		var in []byte
+		var want [][]byte
+		for _, l := range tt.lines {
+			b := mustEncode(t, tt.enc, l)
+			in = append(in, b...)
+			want = append(want, b)
+		}
+		for size := 1; size <= len(in); size++ {
+			got := split(&unitSplitter{size: tt.size, endian: tt.e}, in, size)
+			if len(got) != len(want) {
+				t.Fatalf("%s %q in chunks of %d: got %d lines % x", tt.enc, tt.lines, size, len(got), got)
+			}
+			for i := range got {
+				if !bytes.Equal(got[i], want[i]) {
+					t.Errorf("%s %q in chunks of %d: line %d = % x, want % x", tt.enc, tt.lines, size, i+1, got[i], want[i])
+				}
+			}
+		}
+	}
+}
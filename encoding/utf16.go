@@ -3,7 +3,6 @@ package encoding
 import (
 	"bytes"
 	"code.google.com/p/go.text/encoding/unicode"
-	"container/list"
 	"golang.org/x/text/transform"
 	"io/ioutil"
 	"strings"
@@ -26,8 +25,7 @@ const (
 )
 
 type utf16Encoding struct {
-	*utf16Decoder
-	*utf16splitter
+	*unitSplitter
 
 	endian unicode.Endianness
 	bom    unicode.BOMPolicy
@@ -44,19 +42,69 @@ func (e utf16Encoding) String() string {
 
 func newUtf16Encoding(name string, endian unicode.Endianness, bom unicode.BOMPolicy) *utf16Encoding {
 	return &utf16Encoding{
-		utf16Decoder:  &utf16Decoder{},
-		utf16splitter: &utf16splitter{endian: endian},
-		endian:        endian,
-		bom:           bom,
-		name:          name,
+		unitSplitter: &unitSplitter{size: 2, endian: endian},
+		endian:       endian,
+		bom:          bom,
+		name:         name,
 	}
 }
 
+// unitCost is the score of a code unit before the first ASCII character,
+// more than the bit per byte from 0x80 that makes a candidate implausible.
+const unitCost = 3
+
 type utf16Decoder struct {
-	scoreLE int
-	scoreBE int
+	endian unicode.Endianness
+
+	// Hangul syllables are what pairs of bytes above 0xC0 read as, and
+	// kana what an ASCII digit or punctuation next to any byte reads as,
+	// so they only count once the text has shown an ASCII character; both
+	// languages break their lines, and Korean separates words with spaces.
+	// Until then every code unit costs unitCost, which the first ASCII
+	// character pays back: text without NUL bytes is rarely UTF-16.
+	ascii   bool
+	pending int
+	debt    int
+
+	// validate accepts U+0000 and U+FFFE, which Decode accepts.
+	validate bool
+}
+
+func (c *utf16Encoding) BOM() []byte {
+	if c.endian == unicode.BigEndian {
+		return utf16BEBOM
+	}
+	return utf16LEBOM
+}
+
+func (c *utf16Encoding) encodesBOM() bool {
+	return c.bom == unicode.ExpectBOM
+}
+
+func (c *utf16Encoding) NewEncodingSearcher() EncodingSearcher {
+	return &utf16Decoder{endian: c.endian}
+}
+
+// EncodingSearch checks src with a fresh searcher, so that the UTF-16
+// encodings are EncodingSearchers themselves; a run over several chunks
+// takes the searcher of NewEncodingSearcher, which scores across them.
+func (c *utf16Encoding) EncodingSearch(src []byte, atEOF bool) (nSrc int, err error, score int) {
+	return c.NewEncodingSearcher().EncodingSearch(src, atEOF)
+}
+
+func (c *utf16Encoding) newValidator() EncodingSearcher {
+	return &utf16Decoder{endian: c.endian, validate: true}
 }
 
+func (d *utf16Decoder) unit(c0, c1 byte) uint16 {
+	if d.endian == unicode.BigEndian {
+		return uint16(c0)<<8 | uint16(c1)
+	}
+	return uint16(c1)<<8 | uint16(c0)
+}
+
+// EncodingSearch scores the code units that read as common characters in
+// the decoder's byte order, so that the right byte order scores higher.
 func (d *utf16Decoder) EncodingSearch(p []byte, atEOF bool) (nSrc int, err error, score int) {
 	size := 2
 	n := len(p)
@@ -66,46 +114,59 @@ func (d *utf16Decoder) EncodingSearch(p []byte, atEOF bool) (nSrc int, err error
 	}
 loop:
 	for ; nSrc < n; nSrc += size {
+		size = 2
 
-		c0 := p[nSrc]
-
-		// need first continuation byte
+		// need the second byte of the code unit
 		if n <= nSrc+1 {
-			//return RuneError, 1, true
 			err = transform.ErrShortSrc
 			break loop
 		}
-		c1 := p[nSrc+1]
+		u := d.unit(p[nSrc], p[nSrc+1])
 
-		if c0 == 0x00 && (0x03 < c1 && c1 < 0x20) {
-			d.scoreBE++
-		} else if c1 == 0x00 && (0x03 < c0 && c0 < 0x20) {
-			d.scoreLE++
-		}
-		if surr1 <= c0 && c0 < surr2 {
-			if surr2 <= c1 && c1 < surr3 {
-				//BE
-				d.scoreBE++
-				if d.scoreLE > 0 {
-					err = ErrInvalidEncoding
-					break loop
-				}
-			} else {
-				err = ErrInvalidEncoding
+		switch {
+		case (u == 0x0000 || u == 0xfffe) && !d.validate:
+			err = ErrInvalidEncoding
+			break loop
+
+		case surr1<<8 <= u && u < surr2<<8:
+			// need the low surrogate
+			if n <= nSrc+3 {
+				err = transform.ErrShortSrc
 				break loop
 			}
-		} else if surr1 <= c1 && c1 < surr2 {
-			if surr2 <= c0 && c0 < surr3 {
-				//LE
-				d.scoreLE++
-				if d.scoreLE > 0 {
-					err = ErrInvalidEncoding
-					break loop
-				}
-			} else {
+			u1 := d.unit(p[nSrc+2], p[nSrc+3])
+			if u1 < surr2<<8 || surr3<<8 <= u1 {
 				err = ErrInvalidEncoding
 				break loop
 			}
+			size = 4
+
+		case surr2<<8 <= u && u < surr3<<8:
+			err = ErrInvalidEncoding
+			break loop
+
+		case u == 0x09 || u == 0x0a || u == 0x0d,
+			0x20 <= u && u < 0x7f:
+			if !d.ascii {
+				d.ascii = true
+				score += d.pending + d.debt
+			}
+			score++
+
+		case 0xac00 <= u && u < 0xd7a4,
+			0x3000 <= u && u < 0x3100:
+			if d.ascii {
+				score++
+			} else {
+				d.pending++
+			}
+
+		case 0xff00 <= u && u < 0xfff0:
+			score++
+		}
+		if !d.ascii {
+			score -= unitCost
+			d.debt += unitCost
 		}
 	}
 
@@ -113,18 +174,18 @@ loop:
 		err = ErrInvalidEncoding
 	}
 
-	if d.scoreLE != 0 {
-		score = -d.scoreLE
-	} else if d.scoreBE != 0 {
-		score = +d.scoreBE
-	}
-
 	return nSrc, err, score
 }
 
 func (c *utf16Encoding) getDecoder() transform.Transformer {
 	if c.decoder == nil {
-		c.decoder = unicode.UTF16(c.endian, c.bom).NewDecoder()
+		// Only the first line of a text carries the byte order mark, so
+		// lines are decoded with a mark being optional.
+		bom := c.bom
+		if bom == unicode.ExpectBOM {
+			bom = unicode.UseBOM
+		}
+		c.decoder = unicode.UTF16(c.endian, bom).NewDecoder()
 	} else {
 		c.decoder.Reset()
 	}
@@ -158,79 +219,3 @@ func (c *utf16Encoding) Encode(s string) ([]byte, error) {
 	}
 	return ret, err
 }
-
-type utf16splitter struct {
-	cr      bool
-	remains []byte
-
-	endian unicode.Endianness
-}
-
-func (sp *utf16splitter) reset() {
-	sp.cr = false
-	sp.remains = []byte{}
-}
-
-func (sp *utf16splitter) Split(src []byte, atEnd bool, lines *list.List) {
-
-	start := 0
-	src = append(sp.remains, src...)
-	start = len(sp.remains)
-	if sp.cr {
-		start -= 2
-	}
-	sp.reset()
-
-	n := len(src)
-	nHead := 0
-
-	if sp.endian == unicode.BigEndian {
-		for i := start; i < n; i += 2 {
-			c0 := src[i]
-			c1 := src[i+1]
-			if c0 == 0x00 && c1 == CR {
-				if n <= i+4 {
-					sp.cr = true
-					break
-				}
-
-				c2 := src[i+2]
-				c3 := src[i+3]
-				if c2 == 0x00 && c3 == LE {
-					i += 4
-				}
-				lines.PushBack(src[nHead : i+2])
-				nHead = i + 2
-			} else if c0 == 0x00 && c1 == LE {
-				lines.PushBack(src[nHead : i+2])
-				nHead = i + 2
-			}
-		}
-	} else {
-		for i := start; i < n; i += 2 {
-			c1 := src[i]
-			c0 := src[i+1]
-			if c0 == 0x00 && c1 == CR {
-				if n <= i+4 {
-					sp.cr = true
-					break
-				}
-
-				c3 := src[i+2]
-				c2 := src[i+3]
-				if c2 == 0x00 && c3 == LE {
-					i += 4
-				}
-				lines.PushBack(src[nHead : i+2])
-				nHead = i + 2
-			} else if c0 == 0x00 && c1 == LE {
-				lines.PushBack(src[nHead : i+2])
-				nHead = i + 2
-			}
-		}
-	}
-
-	if nHead <= n-1 {
-		sp.remains = src[nHead:n]
-	}
-}
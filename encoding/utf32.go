@@ -0,0 +1,156 @@
+package encoding
+
+import (
+	"code.google.com/p/go.text/encoding/unicode"
+	"golang.org/x/text/transform"
+	"strings"
+	"unicode/utf8"
+)
+
+var UTF32 *utf32Encoding = newUtf32Encoding("UTF32", unicode.LittleEndian, unicode.ExpectBOM)
+var UTF32B *utf32Encoding = newUtf32Encoding("UTF32B", unicode.BigEndian, unicode.ExpectBOM)
+var UTF32LE *utf32Encoding = newUtf32Encoding("UTF32LE", unicode.LittleEndian, unicode.IgnoreBOM)
+var UTF32BE *utf32Encoding = newUtf32Encoding("UTF32BE", unicode.BigEndian, unicode.IgnoreBOM)
+
+var (
+	utf32LEBOM = []byte{0xff, 0xfe, 0x00, 0x00}
+	utf32BEBOM = []byte{0x00, 0x00, 0xfe, 0xff}
+)
+
+type utf32Encoding struct {
+	*unitSplitter
+
+	endian unicode.Endianness
+	bom    unicode.BOMPolicy
+
+	name string
+}
+
+func (e utf32Encoding) String() string {
+	return e.name
+}
+
+func newUtf32Encoding(name string, endian unicode.Endianness, bom unicode.BOMPolicy) *utf32Encoding {
+	return &utf32Encoding{
+		unitSplitter: &unitSplitter{size: 4, endian: endian},
+		endian:       endian,
+		bom:          bom,
+		name:         name,
+	}
+}
+
+func (c *utf32Encoding) BOM() []byte {
+	if c.endian == unicode.BigEndian {
+		return utf32BEBOM
+	}
+	return utf32LEBOM
+}
+
+func (c *utf32Encoding) encodesBOM() bool {
+	return c.bom == unicode.ExpectBOM
+}
+
+func (c *utf32Encoding) NewEncodingSearcher() EncodingSearcher {
+	return &utf32Decoder{endian: c.endian}
+}
+
+func (c *utf32Encoding) newValidator() EncodingSearcher {
+	return &utf32Decoder{endian: c.endian, validate: true}
+}
+
+type utf32Decoder struct {
+	endian unicode.Endianness
+
+	// validate accepts U+0000 and U+FFFE, which Decode accepts.
+	validate bool
+}
+
+func (d *utf32Decoder) unit(b []byte) rune {
+	if d.endian == unicode.BigEndian {
+		return rune(b[0])<<24 | rune(b[1])<<16 | rune(b[2])<<8 | rune(b[3])
+	}
+	return rune(b[3])<<24 | rune(b[2])<<16 | rune(b[1])<<8 | rune(b[0])
+}
+
+// EncodingSearch scores the code units that read as common characters, as
+// utf16Decoder does.
+func (d *utf32Decoder) EncodingSearch(p []byte, atEOF bool) (nSrc int, err error, score int) {
+	n := len(p)
+	if n < 1 {
+		err = transform.ErrShortSrc
+		return 0, err, 0
+	}
+loop:
+	for ; nSrc < n; nSrc += 4 {
+		// need the whole code unit
+		if n < nSrc+4 {
+			err = transform.ErrShortSrc
+			break loop
+		}
+		u := d.unit(p[nSrc:])
+
+		switch {
+		case (u == 0x0000 || u == 0xfffe) && !d.validate, MaxRune < u || u < 0:
+			err = ErrInvalidEncoding
+			break loop
+
+		case surrogateMin <= u && u <= surrogateMax:
+			err = ErrInvalidEncoding
+			break loop
+
+		case u == 0x09 || u == 0x0a || u == 0x0d,
+			0x20 <= u && u < 0x7f,
+			0x3000 <= u && u < 0x3100,
+			0x4e00 <= u && u < 0xa000,
+			0xac00 <= u && u < 0xd7a4,
+			0xff00 <= u && u < 0xfff0:
+			score++
+		}
+	}
+
+	if atEOF && err == transform.ErrShortSrc {
+		err = ErrInvalidEncoding
+	}
+
+	return nSrc, err, score
+}
+
+// Decode reads code units that are not characters, and an incomplete one
+// at the end, as RuneError, and returns ErrInvalidEncoding with the text.
+func (c *utf32Encoding) Decode(b []byte) (string, error) {
+	if c.bom != unicode.IgnoreBOM {
+		// Only the first line of a text carries the byte order mark.
+		b = TrimBOM(b, c)
+	}
+
+	d := utf32Decoder{endian: c.endian}
+	var buf strings.Builder
+	var err error
+	for ; len(b) >= 4; b = b[4:] {
+		r := d.unit(b)
+		if !utf8.ValidRune(r) {
+			r, err = RuneError, ErrInvalidEncoding
+		}
+		buf.WriteRune(r)
+	}
+	if len(b) > 0 {
+		buf.WriteRune(RuneError)
+		err = ErrInvalidEncoding
+	}
+	return buf.String(), err
+}
+
+func (c *utf32Encoding) Encode(s string) ([]byte, error) {
+	ret := make([]byte, 0, 4*len(s)+4)
+	if c.bom == unicode.ExpectBOM {
+		ret = append(ret, c.BOM()...)
+	}
+	for _, r := range s {
+		if c.endian == unicode.BigEndian {
+			ret = append(ret, byte(r>>24), byte(r>>16), byte(r>>8), byte(r))
+		} else {
+			ret = append(ret, byte(r), byte(r>>8), byte(r>>16), byte(r>>24))
+		}
+	}
+	return ret, nil
+}
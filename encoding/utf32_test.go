@@ -0,0 +1,61 @@
+package encoding
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestUTF32(t *testing.T) {
+	tests := []struct {
+		enc  Encoding
+		s    string
+		want []byte
+	}{
+		{UTF32LE, "a\u20ac\U0001f600", []byte("a\x00\x00\x00\xac\x20\x00\x00\x00\xf6\x01\x00")},
+		{UTF32BE, "a\u20ac\U0001f600", []byte("\x00\x00\x00a\x00\x00\x20\xac\x00\x01\xf6\x00")},
+		{UTF32, "a", []byte("\xff\xfe\x00\x00a\x00\x00\x00")},
+		{UTF32B, "a", []byte("\x00\x00\xfe\xff\x00\x00\x00a")},
+	}
+	for _, tt := range tests {
+		b := mustEncode(t, tt.enc, tt.s)
+		if !bytes.Equal(b, tt.want) {
+			t.Errorf("%s: Encode(%q) = % x, want % x", tt.enc, tt.s, b, tt.want)
+		}
+		if s, err := tt.enc.Decode(b); s != tt.s || err != nil {
+			t.Errorf("%s: Decode(% x) = %q, %v; want %q", tt.enc, b, s, err, tt.s)
+		}
+	}
+}
+
+func TestUTF32Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+	}{
+		{"surrogate", []byte("a\x00\x00\x00\x00\xd8\x00\x00"), "a\ufffd"},
+		{"beyond U+10FFFF", []byte("\x00\x00\x11\x00b\x00\x00\x00"), "\ufffdb"},
+		{"incomplete unit", []byte("a\x00\x00\x00b\x00"), "a\ufffd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := UTF32LE.Decode(tt.in)
+			if s != tt.want || !errors.Is(err, ErrInvalidEncoding) {
+				t.Errorf("Decode = %q, %v; want %q, ErrInvalidEncoding", s, err, tt.want)
+			}
+			if ok, _ := CheckEncodingOf(chunks(tt.in, 3), UTF32LE); ok {
+				t.Errorf("the searcher accepts % x", tt.in)
+			}
+		})
+	}
+}
+
+func TestUTF32Detection(t *testing.T) {
+	for _, enc := range []Encoding{UTF32LE, UTF32BE} {
+		in := mustEncode(t, enc, "hello, world\nこんにちは\n")
+		if det := detect(t, in); det.Encoding != enc {
+			t.Errorf("%s detected as %s", enc, name(det.Encoding))
+		}
+	}
+}
@@ -8,8 +8,6 @@ import (
 var UTF8 utf8Encoding = newUtf8Encoding("UTF8", unicode.IgnoreBOM)
 var UTF8B utf8Encoding = newUtf8Encoding("UTF8B", unicode.ExpectBOM)
 
-var ASCII utf8Encoding = newUtf8Encoding("ASCII", unicode.IgnoreBOM)
-
 const (
 	RuneError = '\uFFFD'     // the "error" Rune or "Unicode replacement character"
 	RuneSelf  = 0x80         // characters below Runeself are represented as themselves in a single byte.
@@ -49,6 +47,10 @@ type utf8Encoding struct {
 	decorder transform.Transformer
 
 	name string
+
+	// validate makes the encoding, as its own EncodingSearcher, accept
+	// NUL bytes.
+	validate bool
 }
 
 func (e utf8Encoding) String() string {
@@ -63,6 +65,19 @@ func newUtf8Encoding(name string, bom unicode.BOMPolicy) utf8Encoding {
 	}
 }
 
+func (e utf8Encoding) BOM() []byte {
+	return utf8BOM
+}
+
+func (e utf8Encoding) NewEncodingSearcher() EncodingSearcher {
+	return e
+}
+
+func (e utf8Encoding) newValidator() EncodingSearcher {
+	e.validate = true
+	return e
+}
+
 func (e utf8Encoding) EncodingSearch(p []byte, atEOF bool) (nSrc int, err error, score int) {
 	size := 0
 	var r rune
@@ -77,7 +92,7 @@ loop:
 		size = 1
 		c0 := p[nSrc]
 
-		if c0 == 0x00 {
+		if c0 == 0x00 && !e.validate {
 			err = ErrInvalidEncoding
 			break loop
 		}
@@ -118,6 +133,7 @@ loop:
 				break loop
 			}
 			//return r, 2, false
+			score++
 			continue
 		}
 
@@ -149,6 +165,7 @@ loop:
 				break loop
 			}
 			//return r, 3, false
+			score++
 			continue
 		}
 
@@ -175,6 +192,7 @@ loop:
 				break loop
 			}
 			//return r, 4, false
+			score++
 			continue
 		}
 
@@ -0,0 +1,137 @@
+package encoding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// VariantConvention is how the characters of JIS X 0208 that the JIS and
+// the Microsoft mapping tables map differently, such as WAVE DASH, are
+// written in Unicode.
+type VariantConvention int
+
+const (
+	// NoConvention leaves the characters as they are.
+	NoConvention VariantConvention = iota
+
+	// JISConvention writes them as the JIS tables do: U+301C WAVE DASH,
+	// U+2016 DOUBLE VERTICAL LINE, U+2212 MINUS SIGN, U+00A2 CENT SIGN and
+	// so on, as used by Shift_JIS and EUC-JP.
+	JISConvention
+
+	// MSConvention writes them as the Microsoft tables do: U+FF5E
+	// FULLWIDTH TILDE, U+2225 PARALLEL TO, U+FF0D FULLWIDTH HYPHEN-MINUS,
+	// U+FFE0 FULLWIDTH CENT SIGN and so on, as used by CP932 and eucJP-ms.
+	MSConvention
+
+	// AutoConvention only applies to encoding. It writes a character in the
+	// other convention where the encoder cannot encode it as it is.
+	AutoConvention
+)
+
+// jisMSVariants pairs the JIS and the Microsoft form of each character.
+var jisMSVariants = [...]struct{ jis, ms rune }{
+	{0x301c, 0xff5e}, // WAVE DASH, FULLWIDTH TILDE
+	{0x2016, 0x2225}, // DOUBLE VERTICAL LINE, PARALLEL TO
+	{0x2212, 0xff0d}, // MINUS SIGN, FULLWIDTH HYPHEN-MINUS
+	{0x00a2, 0xffe0}, // CENT SIGN, FULLWIDTH CENT SIGN
+	{0x00a3, 0xffe1}, // POUND SIGN, FULLWIDTH POUND SIGN
+	{0x00ac, 0xffe2}, // NOT SIGN, FULLWIDTH NOT SIGN
+	{0x2014, 0x2015}, // EM DASH, HORIZONTAL BAR
+	{0x00a6, 0xffe4}, // BROKEN BAR, FULLWIDTH BROKEN BAR
+}
+
+// variant returns the other form of r.
+func variant(r rune) (rune, bool) {
+	for _, v := range jisMSVariants {
+		switch r {
+		case v.jis:
+			return v.ms, true
+		case v.ms:
+			return v.jis, true
+		}
+	}
+	return r, false
+}
+
+// VariantReport counts the characters that normalization replaced, by the
+// character they were.
+type VariantReport map[rune]int
+
+// String lists the replaced characters by code point, one per line, as in
+// "U+FF5E ～ -> U+301C 〜: 3".
+func (r VariantReport) String() string {
+	rs := make([]rune, 0, len(r))
+	for c := range r {
+		rs = append(rs, c)
+	}
+	sort.Slice(rs, func(i, j int) bool { return rs[i] < rs[j] })
+	var buf strings.Builder
+	for _, c := range rs {
+		v, _ := variant(c)
+		fmt.Fprintf(&buf, "U+%04X %c -> U+%04X %c: %d\n", c, c, v, v, r[c])
+	}
+	return buf.String()
+}
+
+// NormalizeVariants returns s with the variant characters written in the
+// convention c, counting the replaced ones in report if it is not nil.
+func NormalizeVariants(s string, c VariantConvention, report VariantReport) string {
+	if c != JISConvention && c != MSConvention {
+		return s
+	}
+	rs := []rune(s)
+	changed := false
+	for i, r := range rs {
+		for _, v := range jisMSVariants {
+			from, to := v.ms, v.jis
+			if c == MSConvention {
+				from, to = v.jis, v.ms
+			}
+			if r == from {
+				rs[i] = to
+				changed = true
+				if report != nil {
+					report[r]++
+				}
+				break
+			}
+		}
+	}
+	if !changed {
+		return s
+	}
+	return string(rs)
+}
+
+// encodeVariants returns s with the variant characters written in the
+// convention c for enc.
+func encodeVariants(enc Encoder, s string, c VariantConvention, report VariantReport) string {
+	if c != AutoConvention {
+		return NormalizeVariants(s, c, report)
+	}
+	rs := []rune(s)
+	changed := false
+	for i, r := range rs {
+		v, ok := variant(r)
+		if !ok {
+			continue
+		}
+		if _, err := enc.Encode(string(r)); err == nil {
+			continue
+		}
+		if _, err := enc.Encode(string(v)); err != nil {
+			continue
+		}
+		rs[i] = v
+		changed = true
+		if report != nil {
+			report[r]++
+		}
+	}
+	if !changed {
+		return s
+	}
+	return string(rs)
+}
@@ -0,0 +1,85 @@
+package encoding
+
+import "testing"
+
+func TestNormalizeVariants(t *testing.T) {
+	const mixed = "〜～‖∥−－¢￠"
+	tests := []struct {
+		name   string
+		c      VariantConvention
+		want   string
+		report VariantReport
+	}{
+		{"JIS", JISConvention, "〜〜‖‖−−¢¢", VariantReport{'～': 1, '∥': 1, '－': 1, '￠': 1}},
+		{"Microsoft", MSConvention, "～～∥∥－－￠￠", VariantReport{'〜': 1, '‖': 1, '−': 1, '¢': 1}},
+		{"none", NoConvention, mixed, VariantReport{}},
+		{"auto", AutoConvention, mixed, VariantReport{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := VariantReport{}
+			if got := NormalizeVariants(mixed, tt.c, report); got != tt.want {
+				t.Errorf("NormalizeVariants = %q, want %q", got, tt.want)
+			}
+			if len(report) != len(tt.report) {
+				t.Errorf("report = %v, want %v", report, tt.report)
+			}
+			for r, n := range tt.report {
+				if report[r] != n {
+					t.Errorf("report[%q] = %d, want %d", r, report[r], n)
+				}
+			}
+		})
+	}
+	if got := NormalizeVariants("〜〜", MSConvention, nil); got != "～～" {
+		t.Errorf("NormalizeVariants without a report = %q", got)
+	}
+}
+
+func TestVariantReportString(t *testing.T) {
+	report := VariantReport{'～': 3, '∥': 1}
+	want := "U+2225 ∥ -> U+2016 ‖: 1\nU+FF5E ～ -> U+301C 〜: 3\n"
+	if got := report.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestEncodeWithVariants(t *testing.T) {
+	tests := []struct {
+		name   string
+		enc    Encoder
+		c      VariantConvention
+		in     string
+		want   string
+		fail   bool
+		report int
+	}{
+		// The x/text tables of Shift_JIS and EUC-JP follow Microsoft.
+		{"auto Shift_JIS", ShiftJIS, AutoConvention, "〜‖", "\x81\x60\x81\x61", false, 2},
+		{"auto EUC-JP", EUCJP, AutoConvention, "−¢", "\xa1\xdd\xa1\xf1", false, 2},
+		{"auto encodable", CP932, AutoConvention, "〜～", "\x81\x60\x81\x60", false, 0},
+		{"auto UTF-8", UTF8, AutoConvention, "〜～", "〜～", false, 0},
+		{"Microsoft", ShiftJIS, MSConvention, "〜", "\x81\x60", false, 1},
+		{"JIS", ShiftJIS, JISConvention, "～", "", true, 1},
+		{"none", ShiftJIS, NoConvention, "〜", "", true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := VariantReport{}
+			b, err := EncodeWith(tt.enc, tt.in, Options{Variants: tt.c, VariantReport: report})
+			if tt.fail != (err != nil) {
+				t.Fatalf("EncodeWith: %v", err)
+			}
+			if !tt.fail && string(b) != tt.want {
+				t.Errorf("EncodeWith = % x, want % x", b, tt.want)
+			}
+			n := 0
+			for _, k := range report {
+				n += k
+			}
+			if n != tt.report {
+				t.Errorf("report counts %d characters, want %d", n, tt.report)
+			}
+		})
+	}
+}
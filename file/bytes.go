@@ -1,10 +1,9 @@
 package file
 
 import (
-	"bufio"
 	"container/list"
+	"errors"
 	"github.com/zackys/go.p/encoding"
-	"golang.org/x/text/transform"
 	"io"
 	"log"
 	"os"
@@ -12,11 +11,14 @@ import (
 
 type Bytes struct {
 	ls *list.List
+
+	// unmap releases the memory-mapped view the content is in, if any.
+	unmap func() error
 }
 
 func NewBytes() *Bytes {
 	return &Bytes{
-		list.New(),
+		ls: list.New(),
 	}
 }
 
@@ -40,113 +42,101 @@ func (itr *Iterator) Next() []byte {
 	return ret
 }
 
-func (c *Bytes) ReadFrom(in *os.File) error {
-	r := bufio.NewReader(in)
+// DefaultChunkSize is the size of the chunks ReadFrom reads.
+const DefaultChunkSize = 64 << 10
+
+// ReadFrom appends the content of in in chunks of DefaultChunkSize.
+func (c *Bytes) ReadFrom(in io.Reader) (int64, error) {
+	return c.ReadChunks(in, DefaultChunkSize)
+}
+
+// ReadChunks appends the content of in in chunks of size bytes; the last
+// chunk may be shorter.
+func (c *Bytes) ReadChunks(in io.Reader, size int) (int64, error) {
+	if size <= 0 {
+		return 0, errors.New("file: chunk size must be positive")
+	}
+	var total int64
 	for {
-		b := make([]byte, 2)
-		n, err := r.Read(b)
-		if n == 0 && err == io.EOF {
-			break
+		b := make([]byte, size)
+		n, err := io.ReadFull(in, b)
+		total += int64(n)
+		if n > 0 {
+			c.ls.PushBack(b[:n])
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
 		} else if err != nil {
-			return err
+			return total, err
 		}
-		c.ls.PushBack(b[:n])
 	}
+}
 
-	return nil
+// Close releases the memory-mapped views of ReadFile and empties c, whose
+// content must not be used after it.
+func (c *Bytes) Close() error {
+	if c.unmap == nil {
+		return nil
+	}
+	err := c.unmap()
+	c.unmap = nil
+	c.ls.Init()
+	return err
 }
 
-func (c *Bytes) WriteTo(out *os.File) error {
+// WriteTo writes the content to out.
+func (c *Bytes) WriteTo(out io.Writer) (int64, error) {
+	var total int64
+	itr := c.Iterator()
+	for itr.HasNext() {
+		n, err := out.Write(itr.Next())
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
 
-	return nil
+// SearchEncoding runs the encodings of encoding.DefaultRegistry over the
+// content and reports them ranked, best first. If only is not empty,
+// detection is restricted to those encodings, which must be registered.
+func (c *Bytes) SearchEncoding(only ...encoding.Encoding) (*encoding.Detection, error) {
+	d, err := encoding.NewDetector(only...)
+	if err != nil {
+		return nil, err
+	}
+	return c.Detect(d), nil
 }
 
-func checkEncoding(c *Bytes, es encoding.EncodingSearcher) (yes bool, score int) {
-	var nSrc, s int
-	var err error
-	var prv []byte
+// Detect feeds the content to d and returns its Detection.
+func (c *Bytes) Detect(d *encoding.Detector) *encoding.Detection {
 	itr := c.Iterator()
 	for itr.HasNext() {
 		b := itr.Next()
-		if prv != nil {
-			b = append(prv, b...)
-		}
+		d.Feed(b, !itr.HasNext())
+	}
 
-		nSrc, err, s = es.EncodingSearch(b, !itr.HasNext())
-		score += s
-		if err == encoding.ErrInvalidEncoding {
-			break
-		} else if err == transform.ErrShortSrc {
-			prv = b[nSrc:]
-		} else {
-			prv = nil
-		}
+	det := d.Detection()
+	for _, cand := range det.Candidates {
+		debug(cand.Encoding, cand.Score, cand.Confidence, cand.Reason)
 	}
+	return det
+}
 
-	return err == nil, score
+// AnalyzeLines detects the encoding of the content line by line, for files
+// that mix encodings and so have none as a whole.
+func (c *Bytes) AnalyzeLines(opts *encoding.AnalyzeOptions) (*encoding.LineAnalysis, error) {
+	return encoding.AnalyzeLines(c.ls, opts)
 }
 
-func (c *Bytes) SearchEncoding() encoding.Encoding {
-	var enc encoding.Encoding
-
-	yes, score := checkEncoding(c, encoding.ISO2022JP)
-	if yes {
-		if score > 0 {
-			//ISO2022確定
-			debug("ISO2022")
-			enc = encoding.ISO2022JP
-		} else {
-			//ASCII確定
-			debug("ASCII")
-			enc = encoding.ASCII
-		}
-	} else {
-		yes, score := checkEncoding(c, encoding.UTF8)
-		if yes {
-			//UTF8確定
-			debug("UTF8")
-			enc = encoding.UTF8
-		} else {
-			sjis, scoreSjis := checkEncoding(c, encoding.ShiftJIS)
-			if sjis {
-				//ShiftJIS確定
-				debug("maybe ShiftJIS")
-			}
-			euc, scoreEuc := checkEncoding(c, encoding.EUCJP)
-			if euc {
-				if !sjis {
-					debug("EUCJP", scoreEuc, scoreSjis)
-					enc = encoding.EUCJP
-				} else if scoreSjis < scoreEuc {
-					debug("EUCJP", scoreEuc, scoreSjis)
-					enc = encoding.EUCJP
-				} else {
-					debug("*ShiftJIS", scoreSjis, scoreEuc)
-					enc = encoding.ShiftJIS
-				}
-			} else {
-				if sjis {
-					debug("**ShiftJIS", scoreSjis, scoreEuc)
-					enc = encoding.ShiftJIS
-				} else {
-					yes, score = checkEncoding(c, encoding.UTF16)
-					if yes {
-						if score > 0 {
-							debug("UTF16BE")
-							enc = encoding.UTF16BE
-						} else {
-							debug("UTF16LE")
-							enc = encoding.UTF16LE
-						}
-					} else {
-						debug("none")
-					}
-				}
-			}
-		}
+// Repair returns the content of a, every run re-encoded in enc.
+func (c *Bytes) Repair(a *encoding.LineAnalysis, enc encoding.Encoding) (*Bytes, error) {
+	ls, err := a.Repair(enc)
+	if err != nil {
+		return nil, err
 	}
-
-	return enc
+	return &Bytes{ls: ls}, nil
 }
 
 func debug(v ...interface{}) {
@@ -0,0 +1,206 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"github.com/zackys/go.p/encoding"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newBytes(t *testing.T, b []byte, size int) *Bytes {
+	t.Helper()
+	c := NewBytes()
+	if _, err := c.ReadChunks(bytes.NewReader(b), size); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// content returns the chunks of c.
+func content(c *Bytes) [][]byte {
+	var bs [][]byte
+	for itr := c.Iterator(); itr.HasNext(); {
+		bs = append(bs, itr.Next())
+	}
+	return bs
+}
+
+func TestReadChunks(t *testing.T) {
+	in := []byte("0123456789")
+	tests := []struct {
+		size   int
+		chunks []string
+	}{
+		{1, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}},
+		{3, []string{"012", "345", "678", "9"}},
+		{5, []string{"01234", "56789"}},
+		{64, []string{"0123456789"}},
+	}
+	for _, tt := range tests {
+		c := NewBytes()
+		n, err := c.ReadChunks(bytes.NewReader(in), tt.size)
+		if err != nil || n != int64(len(in)) {
+			t.Fatalf("ReadChunks(%d) = %d, %v", tt.size, n, err)
+		}
+		got := content(c)
+		if len(got) != len(tt.chunks) {
+			t.Fatalf("ReadChunks(%d): chunks %q, want %q", tt.size, got, tt.chunks)
+		}
+		for i := range got {
+			if string(got[i]) != tt.chunks[i] {
+				t.Errorf("ReadChunks(%d): chunk %d = %q, want %q", tt.size, i, got[i], tt.chunks[i])
+			}
+		}
+	}
+
+	if _, err := NewBytes().ReadChunks(bytes.NewReader(in), 0); err == nil {
+		t.Errorf("ReadChunks(0) did not fail")
+	}
+	errRead := errors.New("read failed")
+	c := NewBytes()
+	n, err := c.ReadChunks(io.MultiReader(bytes.NewReader(in[:4]), &failingReader{errRead}), 3)
+	if err != errRead || n != 4 {
+		t.Errorf("ReadChunks = %d, %v; want 4, %v", n, err, errRead)
+	}
+	if got := content(c); len(got) != 2 || string(got[1]) != "3" {
+		t.Errorf("chunks before the error = %q", got)
+	}
+}
+
+type failingReader struct{ err error }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	big := bytes.Repeat([]byte("日本語のテキスト\n"), DefaultChunkSize/10)
+	tests := []struct {
+		name string
+		in   []byte
+	}{
+		{"empty", nil},
+		{"small", []byte("abc\n")},
+		{"several chunks", big},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(name, tt.in, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c := NewBytes()
+			if err := c.ReadFile(name); err != nil {
+				t.Fatal(err)
+			}
+			got := content(c)
+			for _, b := range got {
+				if len(b) > DefaultChunkSize {
+					t.Errorf("chunk of %d bytes, want at most %d", len(b), DefaultChunkSize)
+				}
+			}
+			if !bytes.Equal(bytes.Join(got, nil), tt.in) {
+				t.Errorf("ReadFile read %d bytes, want %d", len(bytes.Join(got, nil)), len(tt.in))
+			}
+			if len(tt.in) > 0 {
+				det, err := c.SearchEncoding()
+				if err != nil || det.Encoding != encoding.UTF8 && det.Encoding != encoding.ASCII {
+					t.Errorf("SearchEncoding = %v, %v", det, err)
+				}
+			}
+			if err := c.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if c.Close() != nil {
+				t.Errorf("second Close failed")
+			}
+		})
+	}
+	if err := NewBytes().ReadFile(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("ReadFile of a missing file did not fail")
+	}
+}
+
+func TestSearchEncoding(t *testing.T) {
+	sjis, err := encoding.ShiftJIS.Encode("日本語のテキストです。\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		only []encoding.Encoding
+		want encoding.Encoding
+		fail bool
+	}{
+		{"all", nil, encoding.CP932, false},
+		{"only", []encoding.Encoding{encoding.EUCJP, encoding.ShiftJIS}, encoding.ShiftJIS, false},
+		{"unregistered", []encoding.Encoding{encoding.UTF16}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			det, err := newBytes(t, sjis, 5).SearchEncoding(tt.only...)
+			if tt.fail {
+				if err == nil {
+					t.Errorf("SearchEncoding(%v) did not fail", tt.only)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if det.Encoding != tt.want {
+				t.Errorf("Encoding = %v, want %v", det.Encoding, tt.want)
+			}
+		})
+	}
+}
+
+func TestRepair(t *testing.T) {
+	sjis, err := encoding.ShiftJIS.Encode("山田太郎\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	euckr, err := encoding.EUCKR.Encode("김철수\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		in   []byte
+		want string
+		err  error
+	}{
+		{"mixed", append([]byte("abc\nこんにちは\n"), sjis...), "abc\nこんにちは\n山田太郎\n", nil},
+		{"low confidence", append([]byte("abc\n"), euckr...), "", encoding.ErrLowConfidence},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBytes(t, tt.in, 4)
+			a, err := c.AnalyzeLines(nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := c.Repair(a, encoding.UTF8)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Errorf("Repair: %v, want %v", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if _, err := r.WriteTo(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Repair = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
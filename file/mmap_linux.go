@@ -0,0 +1,54 @@
+//go:build linux
+// +build linux
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// ReadFile appends the content of the named file through a read-only
+// memory-mapped view, in chunks of DefaultChunkSize. The view stays mapped
+// until Close; the content must not be used after it.
+func (c *Bytes) ReadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	size := fi.Size()
+	if size == 0 || !fi.Mode().IsRegular() || int64(int(size)) != size {
+		_, err := c.ReadFrom(f)
+		return err
+	}
+
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	mapped, prev := data, c.unmap
+	c.unmap = func() error {
+		err := syscall.Munmap(mapped)
+		if prev != nil {
+			if perr := prev(); err == nil {
+				err = perr
+			}
+		}
+		return err
+	}
+	for len(data) > 0 {
+		n := DefaultChunkSize
+		if n > len(data) {
+			n = len(data)
+		}
+		c.ls.PushBack(data[:n:n])
+		data = data[n:]
+	}
+	return nil
+}
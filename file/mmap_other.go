@@ -0,0 +1,18 @@
+//go:build !linux
+// +build !linux
+
+package file
+
+import "os"
+
+// ReadFile appends the content of the named file in chunks of
+// DefaultChunkSize. Only on Linux is it read through a memory-mapped view.
+func (c *Bytes) ReadFile(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = c.ReadFrom(f)
+	return err
+}
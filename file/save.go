@@ -0,0 +1,158 @@
+package file
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// SaveOption configures Bytes.SaveAs.
+type SaveOption func(*saveConfig)
+
+type saveConfig struct {
+	backup bool
+}
+
+// WithBackup makes Bytes.SaveAs keep the file it replaces as name + ".bak".
+func WithBackup() SaveOption {
+	return func(cfg *saveConfig) {
+		cfg.backup = true
+	}
+}
+
+// SaveAs writes the content to the named file so that it is never left
+// half-written: it writes a temporary file in the same directory, syncs it
+// and renames it over the file. A replaced file keeps its mode and owner;
+// where the system does not let the user give it back its owner, SaveAs
+// fails and leaves the file as it was. A symbolic link is kept and the file
+// it leads to written, whether or not that exists.
+func (c *Bytes) SaveAs(name string, opts ...SaveOption) (err error) {
+	cfg := &saveConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	name, err = resolve(name)
+	if err != nil {
+		return err
+	}
+	var mode os.FileMode = 0644
+	fi, err := os.Stat(name)
+	exists := err == nil
+	if exists {
+		mode = fi.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(name), "."+filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = c.WriteTo(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(mode); err != nil {
+		return err
+	}
+	if exists {
+		if err = chown(tmp, fi); err != nil {
+			return err
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if exists && cfg.backup {
+		if err = backup(name); err != nil {
+			return err
+		}
+	}
+	if err = os.Rename(tmp.Name(), name); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(name))
+}
+
+// maxLinks is the number of symbolic links resolve follows.
+const maxLinks = 40
+
+// resolve follows the symbolic links that name leads through, up to a file
+// that need not exist, and returns the name of that file.
+func resolve(name string) (string, error) {
+	for n := 0; ; n++ {
+		fi, err := os.Lstat(name)
+		if os.IsNotExist(err) {
+			return name, nil
+		}
+		if err != nil {
+			return "", err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return name, nil
+		}
+		if n == maxLinks {
+			return "", &os.PathError{Op: "resolve", Path: name, Err: errors.New("too many symbolic links")}
+		}
+		target, err := os.Readlink(name)
+		if err != nil {
+			return "", err
+		}
+		if !filepath.IsAbs(target) {
+			dir, err := filepath.EvalSymlinks(filepath.Dir(name))
+			if err != nil {
+				return "", err
+			}
+			target = filepath.Join(dir, target)
+		}
+		name = target
+	}
+}
+
+// backup keeps the content of name as name + ".bak", linking it where the
+// file system allows.
+func backup(name string) error {
+	bak := name + ".bak"
+	if err := os.Remove(bak); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if os.Link(name, bak) == nil {
+		return nil
+	}
+
+	in, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	fi, err := in.Stat()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(bak, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fi.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
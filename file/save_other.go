@@ -0,0 +1,16 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package file
+
+import "os"
+
+// chown does nothing where files have no Unix owner.
+func chown(f *os.File, fi os.FileInfo) error {
+	return nil
+}
+
+// syncDir does nothing where directories cannot be synced.
+func syncDir(dir string) error {
+	return nil
+}
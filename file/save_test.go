@@ -0,0 +1,122 @@
+package file
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+type failingWriter struct{ n int }
+
+var errWrite = errors.New("write failed")
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.n < len(p) {
+		n := w.n
+		w.n = 0
+		return n, errWrite
+	}
+	w.n -= len(p)
+	return len(p), nil
+}
+
+func TestWriteTo(t *testing.T) {
+	in := []byte("0123456789")
+	var buf bytes.Buffer
+	n, err := newBytes(t, in, 3).WriteTo(&buf)
+	if err != nil || n != int64(len(in)) || !bytes.Equal(buf.Bytes(), in) {
+		t.Errorf("WriteTo = %d, %v, %q; want %d, nil, %q", n, err, buf.Bytes(), len(in), in)
+	}
+	n, err = newBytes(t, in, 3).WriteTo(&failingWriter{n: 4})
+	if err != errWrite || n != 4 {
+		t.Errorf("WriteTo = %d, %v; want 4, %v", n, err, errWrite)
+	}
+}
+
+func TestSaveAs(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     []byte // nil if the file does not exist
+		mode    os.FileMode
+		link    bool
+		opts    []SaveOption
+		wantBak bool
+	}{
+		{"new file", nil, 0o644, false, nil, false},
+		{"replace", []byte("old\n"), 0o600, false, nil, false},
+		{"backup", []byte("old\n"), 0o640, false, []SaveOption{WithBackup()}, true},
+		{"backup of a new file", nil, 0o644, false, []SaveOption{WithBackup()}, false},
+		{"symbolic link", []byte("old\n"), 0o600, true, []SaveOption{WithBackup()}, true},
+		{"dangling symbolic link", nil, 0o644, true, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.link && runtime.GOOS == "windows" {
+				t.Skip("symbolic links need privileges on Windows")
+			}
+			dir := t.TempDir()
+			target := filepath.Join(dir, "a.txt")
+			if tt.old != nil {
+				if err := os.WriteFile(target, tt.old, tt.mode); err != nil {
+					t.Fatal(err)
+				}
+				// A backup from before is replaced.
+				if err := os.WriteFile(target+".bak", []byte("older\n"), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			name := target
+			if tt.link {
+				name = filepath.Join(dir, "link.txt")
+				if err := os.Symlink(filepath.Base(target), name); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := newBytes(t, []byte("new\n"), 2).SaveAs(name, tt.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if b, err := os.ReadFile(name); err != nil || string(b) != "new\n" {
+				t.Errorf("content = %q, %v", b, err)
+			}
+			fi, err := os.Stat(target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if runtime.GOOS != "windows" && fi.Mode().Perm() != tt.mode {
+				t.Errorf("mode = %v, want %v", fi.Mode().Perm(), tt.mode)
+			}
+			if tt.link {
+				if li, err := os.Lstat(name); err != nil || li.Mode()&os.ModeSymlink == 0 {
+					t.Errorf("%s is no longer a symbolic link", name)
+				}
+			}
+			b, err := os.ReadFile(target + ".bak")
+			switch {
+			case tt.wantBak && (err != nil || string(b) != string(tt.old)):
+				t.Errorf("backup = %q, %v; want %q", b, err, tt.old)
+			case !tt.wantBak && tt.old != nil && string(b) != "older\n":
+				t.Errorf("backup without WithBackup = %q, %v", b, err)
+			}
+			// No temporary file is left.
+			names, err := filepath.Glob(filepath.Join(dir, ".*"))
+			if err != nil || len(names) != 0 {
+				t.Errorf("left %q", names)
+			}
+		})
+	}
+}
+
+func TestSaveAsError(t *testing.T) {
+	dir := t.TempDir()
+	if err := newBytes(t, []byte("new\n"), 2).SaveAs(filepath.Join(dir, "missing", "a.txt")); err == nil {
+		t.Errorf("SaveAs into a missing directory did not fail")
+	}
+	names, err := os.ReadDir(dir)
+	if err != nil || len(names) != 0 {
+		t.Errorf("left %v", names)
+	}
+}
@@ -0,0 +1,35 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// chown gives f the owner and group of fi. It fails where the user may not
+// give them away, rather than leave a file with other owners than the one
+// it replaces.
+func chown(f *os.File, fi os.FileInfo) error {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if cur, err := f.Stat(); err == nil {
+		if c, ok := cur.Sys().(*syscall.Stat_t); ok && c.Uid == st.Uid && c.Gid == st.Gid {
+			return nil
+		}
+	}
+	return f.Chown(int(st.Uid), int(st.Gid))
+}
+
+// syncDir makes a rename in dir durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
@@ -0,0 +1,55 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// ownedBy is an os.FileInfo of a file with other owners.
+type ownedBy struct {
+	os.FileInfo
+	st *syscall.Stat_t
+}
+
+func (fi ownedBy) Sys() interface{} {
+	return fi.st
+}
+
+func TestChown(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "a.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	own := *fi.Sys().(*syscall.Stat_t)
+	other := own
+	other.Uid++
+
+	tests := []struct {
+		name string
+		st   *syscall.Stat_t
+		fail bool
+	}{
+		{"same owner", &own, false},
+		{"other owner", &other, os.Getuid() != 0},
+	}
+	for _, tt := range tests {
+		err := chown(f, ownedBy{fi, tt.st})
+		if tt.fail != (err != nil) {
+			t.Errorf("%s: chown = %v, want failure %v", tt.name, err, tt.fail)
+		}
+		if tt.fail && !errors.Is(err, syscall.EPERM) {
+			t.Errorf("%s: chown = %v, want %v", tt.name, err, syscall.EPERM)
+		}
+	}
+}
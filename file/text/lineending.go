@@ -0,0 +1,137 @@
+package text
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// LineEnding is a line ending style.
+type LineEnding int
+
+const (
+	// KeepLineEnding keeps the line endings as they are.
+	KeepLineEnding LineEnding = iota
+
+	// LF ends lines with "\n", as on Unix.
+	LF
+
+	// CRLF ends lines with "\r\n", as on Windows.
+	CRLF
+
+	// CR ends lines with "\r", as on classic Mac OS.
+	CR
+
+	// NEL ends lines with NEXT LINE, U+0085, as text from EBCDIC does.
+	NEL
+
+	// LS ends lines with LINE SEPARATOR, U+2028.
+	LS
+
+	// PS ends lines with PARAGRAPH SEPARATOR, U+2029.
+	PS
+)
+
+func (le LineEnding) String() string {
+	switch le {
+	case LF:
+		return "\n"
+	case CRLF:
+		return "\r\n"
+	case CR:
+		return "\r"
+	case NEL:
+		return "\u0085"
+	case LS:
+		return "\u2028"
+	case PS:
+		return "\u2029"
+	}
+	return ""
+}
+
+// LineEndings counts the lines of a Text by their line ending.
+type LineEndings struct {
+	CRLF, LF, CR int
+
+	// NEL, LS and PS count the lines broken at Unicode line separators,
+	// see WithUnicodeLineBreaks.
+	NEL, LS, PS int
+
+	// None is the number of lines without a line ending: 1 if the text
+	// does not end in one, 0 otherwise.
+	None int
+}
+
+// Mixed reports whether the text uses more than one line ending.
+func (s LineEndings) Mixed() bool {
+	n := 0
+	for _, c := range []int{s.CRLF, s.LF, s.CR, s.NEL, s.LS, s.PS} {
+		if c > 0 {
+			n++
+		}
+	}
+	return n > 1
+}
+
+// Dominant returns the most common of CRLF, LF and CR, LF if there is none.
+func (s LineEndings) Dominant() LineEnding {
+	switch {
+	case s.CRLF > s.LF && s.CRLF >= s.CR:
+		return CRLF
+	case s.CR > s.LF && s.CR > s.CRLF:
+		return CR
+	}
+	return LF
+}
+
+// LineEndings counts the line endings of c.
+func (c *Text) LineEndings() LineEndings {
+	var s LineEndings
+	itr := c.Iterator()
+	for itr.HasNext() {
+		_, le := cutLineEnding(itr.Next())
+		switch le {
+		case CRLF:
+			s.CRLF++
+		case LF:
+			s.LF++
+		case CR:
+			s.CR++
+		case NEL:
+			s.NEL++
+		case LS:
+			s.LS++
+		case PS:
+			s.PS++
+		default:
+			s.None++
+		}
+	}
+	return s
+}
+
+// cutLineEnding splits the line ending off s.
+func cutLineEnding(s string) (string, LineEnding) {
+	switch {
+	case strings.HasSuffix(s, "\r\n"):
+		return s[:len(s)-2], CRLF
+	case strings.HasSuffix(s, "\n"):
+		return s[:len(s)-1], LF
+	case strings.HasSuffix(s, "\r"):
+		return s[:len(s)-1], CR
+	}
+	r, size := utf8.DecodeLastRuneInString(s)
+	switch r {
+	case '\u0085':
+		return s[:len(s)-size], NEL
+	case '\u2028':
+		return s[:len(s)-size], LS
+	case '\u2029':
+		return s[:len(s)-size], PS
+	}
+	return s, KeepLineEnding
+}
+
+func isUnicodeBreak(r rune) bool {
+	return r == '\u0085' || r == '\u2028' || r == '\u2029'
+}
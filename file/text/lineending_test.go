@@ -0,0 +1,122 @@
+package text
+
+import (
+	"github.com/zackys/go.p/encoding"
+	"testing"
+)
+
+func TestLineEndings(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		want     LineEndings
+		mixed    bool
+		dominant LineEnding
+	}{
+		{"LF", "a\nb\n", LineEndings{LF: 2}, false, LF},
+		{"CRLF", "a\r\nb\r\n", LineEndings{CRLF: 2}, false, CRLF},
+		{"CR", "a\rb\rc", LineEndings{CR: 2, None: 1}, false, CR},
+		{"mixed", "a\r\nb\nc\r\nd", LineEndings{CRLF: 2, LF: 1, None: 1}, true, CRLF},
+		{"tie", "a\r\nb\n", LineEndings{CRLF: 1, LF: 1}, true, LF},
+		{"no line ending", "abc", LineEndings{None: 1}, false, LF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := mustRead(t, encoding.UTF8, []byte(tt.in)).LineEndings()
+			if s != tt.want {
+				t.Errorf("LineEndings() = %+v, want %+v", s, tt.want)
+			}
+			if s.Mixed() != tt.mixed {
+				t.Errorf("Mixed() = %v, want %v", s.Mixed(), tt.mixed)
+			}
+			if s.Dominant() != tt.dominant {
+				t.Errorf("Dominant() = %q, want %q", s.Dominant(), tt.dominant)
+			}
+		})
+	}
+}
+
+func TestWriteToLineEnding(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		enc  encoding.Encoder
+		opts []WriteOption
+		want string
+	}{
+		{"keep", "a\r\nb\nc", encoding.UTF8, nil, "a\r\nb\nc"},
+		{"LF", "a\r\nb\rc\n", encoding.UTF8, []WriteOption{WithLineEnding(LF)}, "a\nb\nc\n"},
+		{"CRLF", "a\nb\n", encoding.UTF8, []WriteOption{WithLineEnding(CRLF)}, "a\r\nb\r\n"},
+		{"CR", "a\nb\r\n", encoding.UTF8, []WriteOption{WithLineEnding(CR)}, "a\rb\r"},
+		{"CRLF in UTF-16", "a\nb", encoding.UTF16LE, []WriteOption{WithLineEnding(CRLF)}, "a\x00\r\x00\n\x00b\x00"},
+		{"final newline", "a\nb", encoding.UTF8, []WriteOption{WithFinalNewline()}, "a\nb\n"},
+		{"final newline of the text", "a\r\nb\r\nc", encoding.UTF8, []WriteOption{WithFinalNewline()}, "a\r\nb\r\nc\r\n"},
+		{"final newline and line ending", "a\nb", encoding.UTF8, []WriteOption{WithFinalNewline(), WithLineEnding(CRLF)}, "a\r\nb\r\n"},
+		{"final newline present", "a\n", encoding.UTF8, []WriteOption{WithFinalNewline()}, "a\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mustRead(t, encoding.UTF8, []byte(tt.in))
+			if got := string(write(t, tx, tt.enc, tt.opts...)); got != tt.want {
+				t.Errorf("WriteTo = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnicodeLineBreaks(t *testing.T) {
+	tests := []struct {
+		name  string
+		enc   encoding.Encoding
+		in    string
+		opts  []ReadOption
+		lines []string
+		stats LineEndings
+	}{
+		{"off", encoding.UTF8, "a\u0085b\u2028c\n", nil, []string{"a\u0085b\u2028c\n"}, LineEndings{LF: 1}},
+		{"UTF-8", encoding.UTF8, "a\u0085b\u2028c\u2029d\n", []ReadOption{WithUnicodeLineBreaks()}, []string{"a\u0085", "b\u2028", "c\u2029", "d\n"}, LineEndings{NEL: 1, LS: 1, PS: 1, LF: 1}},
+		{"ISO-8859-1 NEL", encoding.ISO8859_1, "a\x85b", []ReadOption{WithUnicodeLineBreaks()}, []string{"a\u0085", "b"}, LineEndings{NEL: 1, None: 1}},
+		{"UTF-16", encoding.UTF16LE, "a\x00\x28\x20b\x00", []ReadOption{WithUnicodeLineBreaks()}, []string{"a\u2028", "b"}, LineEndings{LS: 1, None: 1}},
+		{"trailing break", encoding.UTF8, "a\u2028", []ReadOption{WithUnicodeLineBreaks()}, []string{"a\u2028"}, LineEndings{LS: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mustRead(t, tt.enc, []byte(tt.in), tt.opts...)
+			got := lines(tx)
+			if len(got) != len(tt.lines) {
+				t.Fatalf("lines = %q, want %q", got, tt.lines)
+			}
+			for i := range got {
+				if got[i] != tt.lines[i] {
+					t.Errorf("line %d = %q, want %q", i+1, got[i], tt.lines[i])
+				}
+			}
+			if s := tx.LineEndings(); s != tt.stats {
+				t.Errorf("LineEndings() = %+v, want %+v", s, tt.stats)
+			}
+		})
+	}
+}
+
+func TestUnicodeLineBreaksWrite(t *testing.T) {
+	tx := mustRead(t, encoding.UTF8, []byte("a\u0085b\u2028c\n"), WithUnicodeLineBreaks())
+	if got := string(write(t, tx, encoding.UTF8, WithLineEnding(LF))); got != "a\nb\nc\n" {
+		t.Errorf("WriteTo with LF = %q", got)
+	}
+	tx = mustRead(t, encoding.UTF8, []byte("a\nb"))
+	if got := string(write(t, tx, encoding.UTF8, WithLineEnding(LS), WithFinalNewline())); got != "a\u2028b\u2028" {
+		t.Errorf("WriteTo with LS = %q", got)
+	}
+}
+
+func TestUnicodeLineBreaksError(t *testing.T) {
+	// Errors count lines as the splitter does, not at the Unicode breaks.
+	tx, err := read(t, encoding.UTF8, []byte("a\u2028b\xff\nc\n"), WithUnicodeLineBreaks())
+	e, ok := err.(*encoding.Error)
+	if !ok || e.Line != 1 || e.Column != 4 || e.Offset != 5 {
+		t.Fatalf("ReadFrom: %v, want an error at line 1, column 4", err)
+	}
+	if got := lines(tx); len(got) != 3 {
+		t.Errorf("lines = %q, want 3", got)
+	}
+}
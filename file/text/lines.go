@@ -0,0 +1,149 @@
+package text
+
+import (
+	"github.com/zackys/go.p/encoding"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// lineDecoder decodes the split lines of one input in order, as
+// Text.ReadFrom does.
+type lineDecoder struct {
+	enc encoding.Encoding
+	cfg *readConfig
+	dec encoding.Decoder
+
+	// bom is set if the first line started with a byte order mark.
+	bom bool
+	// first is the first error that did not stop the decoding.
+	first error
+
+	offset int64
+	line   int
+}
+
+func newLineDecoder(enc encoding.Encoding, cfg *readConfig) *lineDecoder {
+	ld := &lineDecoder{enc: enc, cfg: cfg, dec: enc}
+	if f, ok := enc.(encoding.LineDecoderFactory); ok && cfg.carryShift {
+		ld.dec = f.NewLineDecoder()
+	}
+	return ld
+}
+
+// decode decodes the line b and passes the lines it makes to push. It
+// returns an error that ends the decoding.
+func (ld *lineDecoder) decode(b []byte, push func(string)) error {
+	start := ld.offset
+	ld.offset += int64(len(b))
+	ld.line++
+	if ld.line == 1 && encoding.HasBOM(b, ld.enc) {
+		ld.bom = true
+		b = encoding.TrimBOM(b, ld.enc)
+		start = ld.offset - int64(len(b))
+	}
+	if ld.cfg.checkShift && ld.first == nil {
+		if err := encoding.ValidateShiftState(ld.enc, b); err != nil {
+			ld.first = locate(err, ld.enc, start, ld.line)
+		}
+	}
+	if ld.cfg.policy != nil {
+		opts := *ld.cfg.policy
+		opts.Decoder = ld.dec
+		str, err := encoding.DecodeWith(ld.enc, b, opts)
+		if err != nil {
+			return locate(err, ld.enc, start, ld.line)
+		}
+		ld.push(str, push)
+		return nil
+	}
+	str, err := ld.dec.Decode(b)
+	if verr := encoding.Validate(ld.enc, b); verr != nil {
+		// It tells where in the line the error is.
+		err = verr
+	}
+	if err != nil && ld.first == nil {
+		ld.first = locate(err, ld.enc, start, ld.line)
+	}
+	ld.push(str, push)
+	return nil
+}
+
+// push passes the decoded line s on, broken at Unicode line separators if
+// the configuration says so.
+func (ld *lineDecoder) push(s string, push func(string)) {
+	if !ld.cfg.unicodeBreaks {
+		push(s)
+		return
+	}
+	for s != "" {
+		i := strings.IndexFunc(s, isUnicodeBreak)
+		if i < 0 {
+			push(s)
+			return
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		push(s[:i+size])
+		s = s[i+size:]
+	}
+}
+
+// locate places err, an error in the line that starts at offset, in the
+// file.
+func locate(err error, enc encoding.Encoding, offset int64, line int) error {
+	e, ok := err.(*encoding.Error)
+	if !ok {
+		e = &encoding.Error{Column: 1, Encoding: enc.String(), Err: err}
+	}
+	e.Offset += offset
+	e.Line = line
+	return e
+}
+
+// lineEncoder encodes the lines of one output in order, as Text.WriteTo
+// does.
+type lineEncoder struct {
+	cfg     *writeConfig
+	session *encoding.EncoderSession
+
+	// final is the line ending WithFinalNewline adds when there is no
+	// WithLineEnding.
+	final LineEnding
+	line  int
+}
+
+// newLineEncoder returns a lineEncoder for text read with a byte order mark
+// if bom is set.
+func newLineEncoder(w io.Writer, enc encoding.Encoder, cfg *writeConfig, bom bool) *lineEncoder {
+	bom = cfg.bom == AddBOM || cfg.bom == KeepBOM && bom
+	final := cfg.eol
+	if final == KeepLineEnding {
+		final = LF
+	}
+	return &lineEncoder{
+		cfg:     cfg,
+		session: encoding.NewEncoderSession(w, enc, bom, cfg.policy),
+		final:   final,
+	}
+}
+
+// encode writes the line s, the last line if last is set.
+func (le *lineEncoder) encode(s string, last bool) error {
+	le.line++
+	if body, eol := cutLineEnding(s); eol != KeepLineEnding && le.cfg.eol != KeepLineEnding {
+		s = body + le.cfg.eol.String()
+	} else if eol == KeepLineEnding && le.cfg.finalNewline && last {
+		s += le.final.String()
+	}
+	if _, err := le.session.WriteString(s); err != nil {
+		if e, ok := err.(*encoding.Error); ok {
+			e.Line = le.line
+		}
+		return err
+	}
+	return nil
+}
+
+func (le *lineEncoder) close() error {
+	return le.session.Close()
+}
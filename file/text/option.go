@@ -0,0 +1,169 @@
+package text
+
+import "github.com/zackys/go.p/encoding"
+
+// BOMMode tells Text.WriteTo whether to write a byte order mark.
+type BOMMode int
+
+const (
+	// KeepBOM writes a byte order mark if the text was read with one.
+	KeepBOM BOMMode = iota
+
+	// StripBOM never writes a byte order mark.
+	StripBOM
+
+	// AddBOM always writes a byte order mark if the encoding has one.
+	AddBOM
+)
+
+// WriteOption configures Text.WriteTo.
+type WriteOption func(*writeConfig)
+
+type writeConfig struct {
+	bom          BOMMode
+	policy       encoding.Options
+	eol          LineEnding
+	finalNewline bool
+}
+
+func newWriteConfig(opts []WriteOption) *writeConfig {
+	cfg := &writeConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// WithBOM sets how the byte order mark is written.
+func WithBOM(m BOMMode) WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.bom = m
+	}
+}
+
+// WithEncodePolicy makes Text.WriteTo handle the characters the encoder
+// cannot encode by opts. Without it, WriteTo fails on them. The Fallback
+// and Variants that opts leaves unset keep what WithFallback and
+// WithVariants set, in whichever order they come.
+func WithEncodePolicy(opts encoding.Options) WriteOption {
+	return func(cfg *writeConfig) {
+		if opts.Fallback == nil {
+			opts.Fallback = cfg.policy.Fallback
+		}
+		if opts.Variants == encoding.NoConvention {
+			opts.Variants = cfg.policy.Variants
+			opts.VariantReport = cfg.policy.VariantReport
+		}
+		cfg.policy = opts
+	}
+}
+
+// ReadOption configures Text.ReadFrom.
+type ReadOption func(*readConfig)
+
+type readConfig struct {
+	policy        *encoding.Options
+	splitter      encoding.Splitter
+	unicodeBreaks bool
+	carryShift    bool
+	checkShift    bool
+}
+
+func newReadConfig(opts []ReadOption) *readConfig {
+	cfg := &readConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// newSplitter returns a fresh splitter for one input in enc: a copy of the
+// one of WithSplitter, or else the one of enc.
+func (cfg *readConfig) newSplitter(enc encoding.Encoding) encoding.Splitter {
+	var sp encoding.Splitter = enc
+	if cfg.splitter != nil {
+		sp = cfg.splitter
+	}
+	if f, ok := sp.(encoding.SplitterFactory); ok {
+		return f.NewSplitter()
+	}
+	return sp
+}
+
+// WithDecodePolicy makes Text.ReadFrom handle invalid input by opts.
+// Without it, ReadFrom reads invalid characters as encoding.RuneError and
+// returns the first of them.
+func WithDecodePolicy(opts encoding.Options) ReadOption {
+	return func(cfg *readConfig) {
+		cfg.policy = &opts
+	}
+}
+
+// WithFallback makes Text.WriteTo consult f for the characters the encoder
+// cannot encode.
+func WithFallback(f *encoding.Fallback) WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.policy.Fallback = f
+	}
+}
+
+// WithVariants makes Text.WriteTo write the characters that the JIS and the
+// Microsoft tables map differently in the convention c, counting the
+// replaced ones in report if it is not nil.
+func WithVariants(c encoding.VariantConvention, report encoding.VariantReport) WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.policy.Variants = c
+		cfg.policy.VariantReport = report
+	}
+}
+
+// WithLineEnding makes Text.WriteTo end every line with le.
+func WithLineEnding(le LineEnding) WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.eol = le
+	}
+}
+
+// WithFinalNewline makes Text.WriteTo end the last line with a line ending
+// if it has none: the one of WithLineEnding, or else the most common one
+// of the text.
+func WithFinalNewline() WriteOption {
+	return func(cfg *writeConfig) {
+		cfg.finalNewline = true
+	}
+}
+
+// WithSplitter makes Text.ReadFrom split the input with sp, such as an
+// encoding.RecordSplitter, instead of into lines. A sp that implements
+// encoding.SplitterFactory is copied for every input.
+func WithSplitter(sp encoding.Splitter) ReadOption {
+	return func(cfg *readConfig) {
+		cfg.splitter = sp
+	}
+}
+
+// WithUnicodeLineBreaks makes Text.ReadFrom also break lines, after
+// decoding, at NEL (U+0085), LINE SEPARATOR (U+2028) and PARAGRAPH
+// SEPARATOR (U+2029). Decode errors still count lines by the splitter.
+func WithUnicodeLineBreaks() ReadOption {
+	return func(cfg *readConfig) {
+		cfg.unicodeBreaks = true
+	}
+}
+
+// WithCarriedShiftState makes Text.ReadFrom carry the shift state of a
+// stateful encoding, such as ISO-2022-JP, from each line to the next, for
+// producers that do not return to ASCII before a newline.
+func WithCarriedShiftState() ReadOption {
+	return func(cfg *readConfig) {
+		cfg.carryShift = true
+	}
+}
+
+// WithShiftStateCheck makes Text.ReadFrom report lines of an ISO-2022
+// encoding that end in a non-ASCII shift state, as encoding.ErrShiftState.
+func WithShiftStateCheck() ReadOption {
+	return func(cfg *readConfig) {
+		cfg.checkShift = true
+	}
+}
@@ -0,0 +1,128 @@
+package text
+
+import (
+	"bufio"
+	"container/list"
+	"github.com/zackys/go.p/encoding"
+	"io"
+)
+
+// Pipeline converts text from a reader to a writer one line at a time: it
+// splits, decodes, transforms and encodes each line before it reads on,
+// so its memory is bounded by the longest line rather than by the file.
+type Pipeline struct {
+	From encoding.Encoding
+	To   encoding.Encoder
+
+	// Transformers are applied to every line in order.
+	Transformers []Transformer
+
+	ReadOptions  []ReadOption
+	WriteOptions []WriteOption
+
+	// ChunkSize is the size of the reads. Zero means 64 KiB.
+	ChunkSize int
+}
+
+const defaultChunkSize = 64 << 10
+
+// Run streams in to out. As Text.ReadFrom does, it returns the first decode
+// error that did not stop it once all of in is written. WithFinalNewline
+// without WithLineEnding adds the line ending most common up to the last
+// line. Every run splits with a fresh splitter, so a Pipeline can run again
+// after an error, and run on several inputs at once.
+func (p *Pipeline) Run(out io.Writer, in io.Reader) error {
+	rcfg := newReadConfig(p.ReadOptions)
+	wcfg := newWriteConfig(p.WriteOptions)
+	sp := rcfg.newSplitter(p.From)
+	size := p.ChunkSize
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+
+	writer := bufio.NewWriter(out)
+	ld := newLineDecoder(p.From, rcfg)
+	var le *lineEncoder
+	var stats LineEndings
+
+	// held is the last line, written once it is known whether another
+	// follows.
+	var held []string
+	flush := func(last bool) error {
+		for len(held) > 0 && (len(held) > 1 || last) {
+			if le == nil {
+				le = newLineEncoder(writer, p.To, wcfg, ld.bom)
+			}
+			if last && len(held) == 1 && wcfg.eol == KeepLineEnding {
+				le.final = stats.Dominant()
+			}
+			if err := le.encode(held[0], last && len(held) == 1); err != nil {
+				return err
+			}
+			held = held[1:]
+		}
+		return nil
+	}
+
+	var terr error
+	push := func(s string) {
+		if terr != nil {
+			return
+		}
+		for _, t := range p.Transformers {
+			if s, terr = t.Transform(s); terr != nil {
+				return
+			}
+		}
+		switch _, eol := cutLineEnding(s); eol {
+		case CRLF:
+			stats.CRLF++
+		case LF:
+			stats.LF++
+		case CR:
+			stats.CR++
+		}
+		held = append(held, s)
+	}
+
+	lines := list.New()
+	for atEnd := false; !atEnd; {
+		chunk := make([]byte, size)
+		n, err := io.ReadFull(in, chunk)
+		switch err {
+		case nil:
+		case io.EOF, io.ErrUnexpectedEOF:
+			atEnd = true
+		default:
+			return err
+		}
+		sp.Split(chunk[:n], atEnd, lines)
+
+		for e := lines.Front(); e != nil; e = lines.Front() {
+			lines.Remove(e)
+			if err := ld.decode(e.Value.([]byte), push); err != nil {
+				return err
+			}
+			if terr != nil {
+				return terr
+			}
+			if err := flush(false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(true); err != nil {
+		return err
+	}
+	if le == nil {
+		le = newLineEncoder(writer, p.To, wcfg, ld.bom)
+	}
+	if err := le.close(); err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return ld.first
+}
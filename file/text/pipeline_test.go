@@ -0,0 +1,137 @@
+package text
+
+import (
+	"bytes"
+	"errors"
+	"github.com/zackys/go.p/encoding"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestPipeline(t *testing.T) {
+	sjis, err := encoding.ShiftJIS.Encode("日本語\r\nテキスト\r\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		p    Pipeline
+		in   []byte
+		want string
+	}{
+		{"Shift_JIS to UTF-8", Pipeline{From: encoding.ShiftJIS, To: encoding.UTF8}, sjis, "日本語\r\nテキスト\r\n"},
+		{"line ending", Pipeline{From: encoding.ShiftJIS, To: encoding.UTF8, WriteOptions: []WriteOption{WithLineEnding(LF)}}, sjis, "日本語\nテキスト\n"},
+		{"UTF-16 with BOM", Pipeline{From: encoding.UTF8, To: encoding.UTF16LE, WriteOptions: []WriteOption{WithBOM(AddBOM)}}, []byte("a\nb\n"), "\xff\xfea\x00\n\x00b\x00\n\x00"},
+		{"keep BOM", Pipeline{From: encoding.UTF16, To: encoding.UTF8B}, []byte("\xff\xfea\x00\n\x00b\x00"), "\xef\xbb\xbfa\nb"},
+		{"transformers", Pipeline{From: encoding.UTF8, To: encoding.EUCJP, Transformers: []Transformer{NewVariantNormalizer(encoding.MSConvention)}}, []byte("〜\n"), "\xa1\xc1\n"},
+		{"final newline", Pipeline{From: encoding.UTF8, To: encoding.UTF8, WriteOptions: []WriteOption{WithFinalNewline()}}, []byte("a\r\nb\r\nc"), "a\r\nb\r\nc\r\n"},
+		{"ISO-2022-JP", Pipeline{From: encoding.UTF8, To: encoding.ISO2022JP}, []byte("日本\n語"), "\x1b$BF|K\\\x1b(B\n\x1b$B8l\x1b(B"},
+		{"empty", Pipeline{From: encoding.UTF8, To: encoding.UTF16LE, WriteOptions: []WriteOption{WithBOM(AddBOM)}}, nil, "\xff\xfe"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, size := range []int{1, 3, 0} {
+				p := tt.p
+				p.ChunkSize = size
+				var out bytes.Buffer
+				if err := p.Run(&out, bytes.NewReader(tt.in)); err != nil {
+					t.Fatalf("Run in chunks of %d: %v", size, err)
+				}
+				if out.String() != tt.want {
+					t.Errorf("Run in chunks of %d = %q, want %q", size, out.String(), tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []ReadOption
+		out  string
+		line int
+		stop bool // whether the error stops the run
+	}{
+		{"default", nil, "a\n�\nb\n", 2, false},
+		{"strict", []ReadOption{WithDecodePolicy(encoding.Options{})}, "a\n", 2, true},
+		{"replace", []ReadOption{WithDecodePolicy(encoding.Options{Policy: encoding.Replace, Replacement: "?"})}, "a\n?\nb\n", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Pipeline{From: encoding.ShiftJIS, To: encoding.UTF8, ReadOptions: tt.opts, ChunkSize: 2}
+			var out bytes.Buffer
+			err := p.Run(&out, strings.NewReader("a\n\xf0@\nb\n"))
+			if tt.line == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if e, ok := err.(*encoding.Error); !ok || e.Line != tt.line || !errors.Is(err, encoding.ErrInvalidEncoding) {
+				t.Fatalf("Run: %v, want an error at line %d", err, tt.line)
+			}
+			if tt.stop {
+				// Lines are written once the next one is decoded.
+				if !strings.HasPrefix(tt.out, out.String()) {
+					t.Errorf("Run wrote %q, want a prefix of %q", out.String(), tt.out)
+				}
+			} else if out.String() != tt.out {
+				t.Errorf("Run wrote %q, want %q", out.String(), tt.out)
+			}
+		})
+	}
+}
+
+func TestPipelineRunAgain(t *testing.T) {
+	p := Pipeline{From: encoding.ShiftJIS, To: encoding.UTF8, ReadOptions: []ReadOption{WithDecodePolicy(encoding.Options{})}, ChunkSize: 9}
+	// The run stops at the first line while "ghijkl" waits for its line
+	// ending.
+	if err := p.Run(&bytes.Buffer{}, strings.NewReader("\xf0\x40\nghijkl\n")); err == nil {
+		t.Fatal("Run did not fail")
+	}
+	var out bytes.Buffer
+	if err := p.Run(&out, strings.NewReader("hello\n")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello\n" {
+		t.Errorf("second Run = %q, want %q", out.String(), "hello\n")
+	}
+
+	// A record splitter of WithSplitter starts afresh as well.
+	sp, err := encoding.NewDelimiterSplitter(encoding.ShiftJIS, "@")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReadOptions = append(p.ReadOptions, WithSplitter(sp))
+	if err := p.Run(&bytes.Buffer{}, strings.NewReader("\xf0\x40@ghijkl@")); err == nil {
+		t.Fatal("Run with a record splitter did not fail")
+	}
+	out.Reset()
+	if err := p.Run(&out, strings.NewReader("hello@")); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != "hello@" {
+		t.Errorf("second Run with a record splitter = %q, want %q", out.String(), "hello@")
+	}
+}
+
+func TestPipelineConcurrent(t *testing.T) {
+	p := Pipeline{From: encoding.UTF8, To: encoding.UTF8, ChunkSize: 3}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		in := strings.Repeat(string(rune('a'+i))+"bcdefg\n", 100)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out bytes.Buffer
+			if err := p.Run(&out, strings.NewReader(in)); err != nil {
+				t.Error(err)
+				return
+			}
+			if out.String() != in {
+				t.Errorf("Run mixed the inputs of concurrent runs")
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -12,12 +12,14 @@ type Text struct {
 	ls *list.List
 
 	encoding encoding.Encoding
+
+	bom bool
 }
 
 func New(encoding encoding.Encoding) *Text {
 	return &Text{
-		list.New(),
-		encoding,
+		ls:       list.New(),
+		encoding: encoding,
 	}
 }
 
@@ -25,6 +27,11 @@ func (c *Text) Encoding() encoding.Encoding {
 	return c.encoding
 }
 
+// HasBOM reports whether the text was read with a byte order mark.
+func (c *Text) HasBOM() bool {
+	return c.bom
+}
+
 type Iterator struct {
 	next *list.Element
 }
@@ -45,17 +52,27 @@ func (itr *Iterator) Next() string {
 	return ret
 }
 
-func (c *Text) ReadFrom(in *file.Bytes) {
+// ReadFrom decodes the lines of in. Invalid characters are read as
+// encoding.RuneError, and the first of them is returned as an
+// *encoding.Error, unless a decode policy says otherwise.
+func (c *Text) ReadFrom(in *file.Bytes, opts ...ReadOption) error {
+	cfg := newReadConfig(opts)
+	sp := cfg.newSplitter(c.encoding)
 	ls := list.New()
 	itr := in.Iterator()
 	for itr.HasNext() {
 		b := itr.Next()
-		c.encoding.Split(b, !itr.HasNext(), ls)
+		sp.Split(b, !itr.HasNext(), ls)
 	}
+
+	ld := newLineDecoder(c.encoding, cfg)
 	for e := ls.Front(); e != nil; e = e.Next() {
-		str, _ := c.encoding.Decode(e.Value.([]byte))
-		c.ls.PushBack(str)
+		if err := ld.decode(e.Value.([]byte), func(s string) { c.ls.PushBack(s) }); err != nil {
+			return err
+		}
 	}
+	c.bom = ld.bom
+	return ld.first
 }
 
 func (c *Text) Transform(t ...Transformer) error {
@@ -77,16 +94,22 @@ func (c *Text) Transform(t ...Transformer) error {
 	return nil
 }
 
-func (c *Text) WriteTo(out io.Writer, enc encoding.Encoder) error {
+func (c *Text) WriteTo(out io.Writer, enc encoding.Encoder, opts ...WriteOption) error {
+	cfg := newWriteConfig(opts)
 	writer := bufio.NewWriter(out)
+	le := newLineEncoder(writer, enc, cfg, c.bom)
+	if cfg.finalNewline && cfg.eol == KeepLineEnding {
+		le.final = c.LineEndings().Dominant()
+	}
+
 	itr := c.Iterator()
 	for itr.HasNext() {
-		b, err := enc.Encode(itr.Next())
-		if err != nil {
+		if err := le.encode(itr.Next(), !itr.HasNext()); err != nil {
 			return err
-		} else {
-			writer.Write(b)
 		}
 	}
+	if err := le.close(); err != nil {
+		return err
+	}
 	return writer.Flush()
 }
@@ -0,0 +1,208 @@
+package text
+
+import (
+	"bytes"
+	"errors"
+	"github.com/zackys/go.p/encoding"
+	"github.com/zackys/go.p/file"
+	"strings"
+	"testing"
+)
+
+// read reads in, fed in chunks of 3 bytes, as enc.
+func read(t *testing.T, enc encoding.Encoding, in []byte, opts ...ReadOption) (*Text, error) {
+	t.Helper()
+	b := file.NewBytes()
+	if _, err := b.ReadChunks(bytes.NewReader(in), 3); err != nil {
+		t.Fatal(err)
+	}
+	tx := New(enc)
+	return tx, tx.ReadFrom(b, opts...)
+}
+
+func mustRead(t *testing.T, enc encoding.Encoding, in []byte, opts ...ReadOption) *Text {
+	t.Helper()
+	tx, err := read(t, enc, in, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func lines(tx *Text) []string {
+	var ss []string
+	for itr := tx.Iterator(); itr.HasNext(); {
+		ss = append(ss, itr.Next())
+	}
+	return ss
+}
+
+func write(t *testing.T, tx *Text, enc encoding.Encoder, opts ...WriteOption) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := tx.WriteTo(&buf, enc, opts...); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriteToBOM(t *testing.T) {
+	withBOM := []byte("\xef\xbb\xbfa\n\xef\xbb\xbfb\n")
+	plain := []byte("a\n\xef\xbb\xbfb\n")
+	tests := []struct {
+		name string
+		in   []byte
+		enc  encoding.Encoder
+		mode BOMMode
+		want []byte
+	}{
+		{"keep", withBOM, encoding.UTF8, KeepBOM, withBOM},
+		{"keep none", plain, encoding.UTF8, KeepBOM, plain},
+		{"strip", withBOM, encoding.UTF8, StripBOM, plain},
+		{"add", plain, encoding.UTF8, AddBOM, withBOM},
+		{"add UTF-16", plain, encoding.UTF16LE, AddBOM, []byte("\xff\xfea\x00\n\x00\xff\xfeb\x00\n\x00")},
+		{"strip UTF-16", withBOM, encoding.UTF16, StripBOM, []byte("a\x00\n\x00\xff\xfeb\x00\n\x00")},
+		{"keep UTF-16", withBOM, encoding.UTF16, KeepBOM, []byte("\xff\xfea\x00\n\x00\xff\xfeb\x00\n\x00")},
+		{"keep UTF-32", withBOM, encoding.UTF32B, KeepBOM, []byte("\x00\x00\xfe\xff\x00\x00\x00a\x00\x00\x00\n\x00\x00\xfe\xff\x00\x00\x00b\x00\x00\x00\n")},
+		{"no mark", []byte("a\nb\n"), encoding.ShiftJIS, AddBOM, []byte("a\nb\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mustRead(t, encoding.UTF8, tt.in)
+			if want := bytes.HasPrefix(tt.in, []byte("\xef\xbb\xbf")); tx.HasBOM() != want {
+				t.Errorf("HasBOM() = %v, want %v", tx.HasBOM(), want)
+			}
+			if got := write(t, tx, tt.enc, WithBOM(tt.mode)); !bytes.Equal(got, tt.want) {
+				t.Errorf("WriteTo = % x, want % x", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFromUTF32(t *testing.T) {
+	in := []byte("a\x00\x00\x00\n\x00\x00\x00b\x00\x00\x00\x00\xd8\x00\x00\n\x00\x00\x00")
+	tx, err := read(t, encoding.UTF32LE, in)
+	e, ok := err.(*encoding.Error)
+	if !ok || e.Line != 2 || e.Column != 2 || e.Offset != 12 || !errors.Is(err, encoding.ErrInvalidEncoding) {
+		t.Fatalf("ReadFrom: %#v, want an invalid encoding error at line 2, column 2", err)
+	}
+	if got := lines(tx); len(got) != 2 || got[0] != "a\n" || got[1] != "b\ufffd\n" {
+		t.Errorf("lines = %q", got)
+	}
+}
+
+func TestReadFromValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		enc  encoding.Encoding
+		in   []byte
+		want string
+		col  int // column of the error, 0 if none
+	}{
+		{"UTF-8 NUL", encoding.UTF8, []byte("a\x00b\n"), "a\x00b\n", 0},
+		{"UTF-16 NUL", encoding.UTF16LE, []byte("a\x00\x00\x00\n\x00"), "a\x00\n", 0},
+		{"ASCII escape", encoding.ASCII, []byte("\x1b[0m\n"), "\x1b[0m\n", 0},
+		{"Shift_JIS NEC special character", encoding.ShiftJIS, []byte("\x87\x40\n"), "①\n", 0},
+		{"UTF-8 invalid", encoding.UTF8, []byte("a\xffb\n"), "a\xffb\n", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := read(t, tt.enc, tt.in)
+			if tt.col == 0 && err != nil {
+				t.Fatalf("ReadFrom: %v", err)
+			}
+			if tt.col != 0 {
+				e, ok := err.(*encoding.Error)
+				if !ok || e.Line != 1 || e.Column != tt.col {
+					t.Fatalf("ReadFrom: %v, want an error at line 1, column %d", err, tt.col)
+				}
+			}
+			if got := lines(tx); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("lines = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteToFallback(t *testing.T) {
+	f, err := encoding.LoadFallback(strings.NewReader("ⅰ → i\n😀 → :)\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opts []WriteOption
+		want string
+		fail bool
+	}{
+		{"without fallback", nil, "", true},
+		{"fallback", []WriteOption{WithFallback(f)}, "i :)\n", false},
+		{"fallback and policy", []WriteOption{WithFallback(f), WithEncodePolicy(encoding.Options{Policy: encoding.Replace})}, "i :)\n", false},
+		{"policy and fallback", []WriteOption{WithEncodePolicy(encoding.Options{Policy: encoding.Replace}), WithFallback(f)}, "i :)\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := mustRead(t, encoding.UTF8, []byte("ⅰ 😀\n"))
+			var buf bytes.Buffer
+			err := tx.WriteTo(&buf, encoding.ASCII, tt.opts...)
+			if tt.fail {
+				if err == nil {
+					t.Errorf("WriteTo did not fail")
+				}
+				return
+			}
+			if err != nil || buf.String() != tt.want {
+				t.Errorf("WriteTo = %q, %v; want %q", buf.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFromSplitter(t *testing.T) {
+	sp, err := encoding.NewDelimiterSplitter(encoding.ShiftJIS, "@")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := mustRead(t, encoding.ShiftJIS, []byte("x\x87\x40y@z"), WithSplitter(sp))
+	if got := lines(tx); len(got) != 2 || got[0] != "x①y@" || got[1] != "z" {
+		t.Errorf("lines = %q", got)
+	}
+}
+
+func TestReadFromShiftState(t *testing.T) {
+	in := []byte("\x1b$BF|\nK\\\x1b(B\na\n")
+	tests := []struct {
+		name  string
+		opts  []ReadOption
+		lines []string
+		line  int // line of the error, 0 if none
+	}{
+		{"per line", nil, []string{"日\n", "K\\\n", "a\n"}, 0},
+		{"carried", []ReadOption{WithCarriedShiftState()}, []string{"日\n", "本\n", "a\n"}, 0},
+		{"checked", []ReadOption{WithShiftStateCheck()}, []string{"日\n", "K\\\n", "a\n"}, 1},
+		{"carried and checked", []ReadOption{WithCarriedShiftState(), WithShiftStateCheck()}, []string{"日\n", "本\n", "a\n"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := read(t, encoding.ISO2022JP, in, tt.opts...)
+			if tt.line == 0 && err != nil {
+				t.Fatalf("ReadFrom: %v", err)
+			}
+			if tt.line != 0 {
+				e, ok := err.(*encoding.Error)
+				if !ok || e.Line != tt.line || !errors.Is(err, encoding.ErrShiftState) {
+					t.Fatalf("ReadFrom: %v, want %v at line %d", err, encoding.ErrShiftState, tt.line)
+				}
+			}
+			got := lines(tx)
+			if len(got) != len(tt.lines) {
+				t.Fatalf("lines = %q, want %q", got, tt.lines)
+			}
+			for i := range got {
+				if got[i] != tt.lines[i] {
+					t.Errorf("line %d = %q, want %q", i+1, got[i], tt.lines[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package text
+
+import "github.com/zackys/go.p/encoding"
+
+// VariantNormalizer is a Transformer that writes the characters that the
+// JIS and the Microsoft tables map differently, such as WAVE DASH and
+// FULLWIDTH TILDE, in one convention.
+type VariantNormalizer struct {
+	Convention encoding.VariantConvention
+
+	// Report counts the characters replaced over all lines.
+	Report encoding.VariantReport
+}
+
+// NewVariantNormalizer returns a VariantNormalizer for the convention c.
+func NewVariantNormalizer(c encoding.VariantConvention) *VariantNormalizer {
+	return &VariantNormalizer{
+		Convention: c,
+		Report:     encoding.VariantReport{},
+	}
+}
+
+func (n *VariantNormalizer) Transform(src string) (string, error) {
+	return encoding.NormalizeVariants(src, n.Convention, n.Report), nil
+}
@@ -0,0 +1,47 @@
+package text
+
+import (
+	"bytes"
+	"github.com/zackys/go.p/encoding"
+	"testing"
+)
+
+func TestVariantNormalizer(t *testing.T) {
+	tests := []struct {
+		c    encoding.VariantConvention
+		want []string
+	}{
+		{encoding.JISConvention, []string{"〜〜\n", "‖−\n"}},
+		{encoding.MSConvention, []string{"～～\n", "∥－\n"}},
+	}
+	for _, tt := range tests {
+		tx := mustRead(t, encoding.UTF8, []byte("〜～\n‖－\n"))
+		n := NewVariantNormalizer(tt.c)
+		if err := tx.Transform(n); err != nil {
+			t.Fatal(err)
+		}
+		got := lines(tx)
+		if len(got) != len(tt.want) || got[0] != tt.want[0] || got[1] != tt.want[1] {
+			t.Errorf("%v: lines = %q, want %q", tt.c, got, tt.want)
+		}
+		if total := n.Report['〜'] + n.Report['～'] + n.Report['‖'] + n.Report['－']; total != 2 {
+			t.Errorf("%v: report = %v, want 2 characters", tt.c, n.Report)
+		}
+	}
+}
+
+func TestWriteToVariants(t *testing.T) {
+	tx := mustRead(t, encoding.UTF8, []byte("〜‖\n"))
+	report := encoding.VariantReport{}
+	got := write(t, tx, encoding.EUCJP, WithVariants(encoding.AutoConvention, report))
+	if want := []byte("\xa1\xc1\xa1\xc2\n"); !bytes.Equal(got, want) {
+		t.Errorf("WriteTo = % x, want % x", got, want)
+	}
+	if report['〜'] != 1 || report['‖'] != 1 {
+		t.Errorf("report = %v", report)
+	}
+	var buf bytes.Buffer
+	if err := tx.WriteTo(&buf, encoding.EUCJP); err == nil {
+		t.Errorf("WriteTo without WithVariants did not fail")
+	}
+}